@@ -0,0 +1,733 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rbisewski/scaffolding/odt"
+	"github.com/rbisewski/scaffolding/rosewood"
+)
+
+// convert ... convert the given Rosewood tables into an ODT, CSV or JSON file
+func convert(args []string) error {
+
+	var config = Config{
+		tables:    "",
+		inputDir:  ".",
+		outputDir: ".",
+	}
+
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	err := setupArguments(flags, &config)
+	if err != nil {
+		return err
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// if the version flag has been set to true, print the version
+	// information and quit
+	if PrintVersionArgument {
+		fmt.Printf("Scaffolding ODT Generator v%s, Build: %s\n", Version, Build)
+		return nil
+	}
+
+	// -csv is shorthand for -format csv
+	if PrintAsCSV {
+		OutputFormat = "csv"
+	}
+	OutputFormat = strings.ToLower(OutputFormat)
+
+	// by default, use the current directory if none is specified
+	if config.inputDir == "" {
+		config.inputDir = "."
+	}
+	if config.outputDir == "" {
+		config.outputDir = "."
+	}
+
+	// validate input
+	if err := validArgument(&config); err != nil {
+		fmt.Println(usageMessage)
+		return err
+	}
+
+	// start from a journal style preset, if one was given
+	if config.preset != "" {
+		preset, ok := odt.Presets[strings.ToLower(config.preset)]
+		if !ok {
+			return fmt.Errorf("Invalid preset: %s. Please use apa, ama or nejm.", config.preset)
+		}
+		TableTheme = preset
+	}
+
+	// read in the theme file, if one was given
+	if config.themeFile != "" {
+		TableTheme, err = odt.ReadTheme(config.themeFile, TableTheme)
+		if err != nil {
+			return err
+		}
+	}
+
+	report, err := newReport(&config)
+	if err != nil {
+		return err
+	}
+
+	// create output paths to for where the files will be generated
+	defaultOutputFilename := DefaultODTOutputFilename
+	switch OutputFormat {
+	case "csv":
+		defaultOutputFilename = DefaultCSVOutputFilename
+	case "json":
+		defaultOutputFilename = DefaultJSONOutputFilename
+	}
+	outputFilepath := filepath.Join(config.outputDir, defaultOutputFilename)
+
+	err = report.write(outputFilepath)
+
+	// keep rebuilding the output as the tables change, if requested
+	if config.watch {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\n%s\n", err)
+		}
+		report.watch(outputFilepath)
+	}
+
+	return err
+}
+
+// validate ... check each of the given Rosewood tables for problems, without producing output
+func validate(args []string) error {
+
+	var config = Config{inputDir: "."}
+
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Println(validateUsageMessage)
+	}
+	flags.StringVar(&config.tables, "tables", "", "")
+	flags.StringVar(&config.inputDir, "indir", ".", "")
+	flags.IntVar(&config.jobs, "jobs", runtime.NumCPU(), "")
+	printAsJSON := flags.Bool("json", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if config.tables == "" {
+		fmt.Println(validateUsageMessage)
+		return fmt.Errorf("Invalid table names. Please enter a valid list of tables.")
+	}
+
+	names, paths := splitTables(&config)
+
+	// each file is read once, so that it is linted and parsed as it was
+	// at the same moment
+	tables := make([]*rosewood.Table, len(paths))
+	validations := make([]Validation, len(paths))
+	runJobs(len(paths), config.jobs, func(i int) {
+		validations[i], tables[i] = validateFile(names[i], paths[i])
+	})
+
+	// tables of the same title as an earlier one are taken to be duplicates
+	titles := make(map[string]string)
+
+	failureCount := 0
+	for i, name := range names {
+
+		validation := &validations[i]
+
+		if tables[i] != nil {
+			if earlier, ok := titles[tables[i].Title]; ok {
+				validation.Problems = append(validation.Problems, rosewood.Problem{
+					Rule:     "duplicate",
+					Severity: rosewood.SeverityError,
+					Message:  "table has the same title as " + earlier,
+				})
+			} else {
+				titles[tables[i].Title] = name
+			}
+		}
+
+		for _, problem := range validation.Problems {
+			if problem.Severity == rosewood.SeverityError {
+				validation.Valid = false
+			}
+		}
+		if !validation.Valid {
+			failureCount++
+		}
+	}
+
+	if *printAsJSON {
+		output, err := json.MarshalIndent(validations, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(output))
+	} else {
+		for _, validation := range validations {
+			if len(validation.Problems) == 0 {
+				fmt.Printf("%s: ok\n", validation.Table)
+			}
+			for _, problem := range validation.Problems {
+				location := validation.Table
+				if problem.Line > 0 {
+					location += ":" + strconv.Itoa(problem.Line)
+				}
+				fmt.Printf("%s: %s: %s [%s]\n", location, problem.Severity, problem.Message, problem.Rule)
+			}
+		}
+	}
+
+	if failureCount > 0 {
+		return fmt.Errorf("%d of %d tables are invalid", failureCount, len(names))
+	}
+
+	return nil
+}
+
+// validateFile ... lint and parse the given Rosewood or JSON file, passing back its problems and
+// its table, if it could be parsed
+func validateFile(name string, path string) (Validation, *rosewood.Table) {
+
+	validation := Validation{Table: name, Path: path, Valid: true}
+
+	byteContents, err := ioutil.ReadFile(path)
+	if err != nil {
+		validation.Problems = []rosewood.Problem{{
+			Rule:     "read",
+			Severity: rosewood.SeverityError,
+			Message:  err.Error(),
+		}}
+		return validation, nil
+	}
+
+	// empty files are converted to nothing, rather than being invalid
+	validation.Problems = make([]rosewood.Problem, 0)
+	if len(byteContents) == 0 {
+		return validation, nil
+	}
+
+	// the layout of JSON tables is checked by parsing them alone
+	if !rosewood.IsJSON(byteContents) {
+		validation.Problems = rosewood.Lint(byteContents)
+	}
+
+	table, err := parseTable(byteContents)
+	if err != nil {
+		validation.Problems = append(validation.Problems, rosewood.Problem{
+			Rule:     "parse",
+			Severity: rosewood.SeverityError,
+			Message:  err.Error(),
+		})
+		return validation, nil
+	}
+
+	return validation, table
+}
+
+// inspect ... print the parsed structure of each of the given Rosewood tables
+func inspect(args []string) error {
+
+	var config = Config{inputDir: "."}
+
+	flags := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Println(inspectUsageMessage)
+	}
+	flags.StringVar(&config.tables, "tables", "", "")
+	flags.StringVar(&config.inputDir, "indir", ".", "")
+	printAsJSON := flags.Bool("json", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// tables may be named via -tables, or given as paths of their own
+	names := make([]string, 0)
+	paths := make([]string, 0)
+	if config.tables != "" {
+		names, paths = splitTables(&config)
+	}
+	names = append(names, flags.Args()...)
+	paths = append(paths, flags.Args()...)
+
+	if len(paths) == 0 {
+		fmt.Println(inspectUsageMessage)
+		return fmt.Errorf("Invalid table names. Please enter a valid list of tables.")
+	}
+
+	tables, errs := readTables(paths, 1, nil)
+
+	inspections := make([]Inspection, 0, len(tables))
+	for i, table := range tables {
+		if errs[i] != nil {
+			return fmt.Errorf("%s: %s", names[i], errs[i])
+		}
+		inspections = append(inspections, newInspection(names[i], paths[i], table))
+	}
+
+	if *printAsJSON {
+		output, err := json.MarshalIndent(inspections, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	for _, inspection := range inspections {
+		fmt.Print(inspection.tree())
+	}
+
+	return nil
+}
+
+// newInspection ... describe the structure of a parsed table; empty files give a nil table, and
+// hence an inspection of no columns
+func newInspection(name string, path string, table *rosewood.Table) Inspection {
+
+	inspection := Inspection{
+		Table: name,
+		Path:  path,
+		Rows:  make([]InspectedRow, 0),
+	}
+
+	if table == nil {
+		return inspection
+	}
+
+	inspection.Title = table.Title
+	inspection.Columns = table.Columns
+	inspection.HeaderRows = len(table.Headers)
+	for _, cells := range table.Headers {
+		inspection.Headers = append(inspection.Headers, inspectRow(cells, table.Columns))
+	}
+	for _, cells := range table.Rows {
+		inspection.Rows = append(inspection.Rows, inspectRow(cells, table.Columns))
+	}
+
+	return inspection
+}
+
+// inspectRow ... describe the cells of a row of a table of the given number of columns
+func inspectRow(cells []rosewood.Cell, columns int) InspectedRow {
+
+	row := InspectedRow{
+		Cells:   make([]InspectedCell, 0, len(cells)),
+		Padding: columns - rosewood.RowColumns(cells),
+	}
+
+	for _, cell := range cells {
+
+		inspected := InspectedCell{
+			Text:   cell.Text,
+			Indent: cell.Indent,
+			Bold:   cell.Bold,
+			Span:   cell.Spanned(),
+			Type:   "text",
+		}
+
+		if valueType, value, ok := cell.Value(); ok {
+			inspected.Type = valueType
+			inspected.Value = &value
+		} else if cell.IsInterval() {
+			inspected.Type = "interval"
+		} else if cell.Text == "" {
+			inspected.Type = "empty"
+		}
+
+		row.Cells = append(row.Cells, inspected)
+	}
+
+	return row
+}
+
+// tree ... draw the inspection as a tree of the title, header and body, with a line per cell
+func (i Inspection) tree() string {
+
+	result := i.Table
+	if i.Path != i.Table {
+		result += " (" + i.Path + ")"
+	}
+	result += "\n"
+
+	if i.Columns == 0 {
+		return result + "└── empty\n"
+	}
+
+	result += "├── title: " + i.Title + "\n"
+	result += "├── columns: " + strconv.Itoa(i.Columns) + "\n"
+
+	result += "├── header rows: " + strconv.Itoa(i.HeaderRows) + "\n"
+	for n, row := range i.Headers {
+		if n == len(i.Headers)-1 {
+			result += "│   └── row " + strconv.Itoa(n+1) + "\n" + row.tree("│       ")
+		} else {
+			result += "│   ├── row " + strconv.Itoa(n+1) + "\n" + row.tree("│   │   ")
+		}
+	}
+
+	result += "└── body rows: " + strconv.Itoa(len(i.Rows)) + "\n"
+	for n, row := range i.Rows {
+		if n == len(i.Rows)-1 {
+			result += "    └── row " + strconv.Itoa(n+1) + "\n" + row.tree("        ")
+		} else {
+			result += "    ├── row " + strconv.Itoa(n+1) + "\n" + row.tree("    │   ")
+		}
+	}
+
+	return result
+}
+
+// tree ... draw a line per cell of the row, each beginning with the given indent
+func (r InspectedRow) tree(indent string) string {
+
+	result := ""
+	for c, cell := range r.Cells {
+
+		leaf := "├── "
+		if c == len(r.Cells)-1 && r.Padding == 0 {
+			leaf = "└── "
+		}
+
+		result += indent + leaf + strconv.Quote(cell.Text) + " " + cell.Type
+		if cell.Value != nil {
+			result += " " + strconv.FormatFloat(*cell.Value, 'g', -1, 64)
+		}
+		if cell.Indent > 0 {
+			result += ", indent " + strconv.Itoa(cell.Indent)
+		}
+		if cell.Bold {
+			result += ", bold"
+		}
+		if cell.Span > 1 {
+			result += ", spans " + strconv.Itoa(cell.Span) + " columns"
+		}
+		result += "\n"
+	}
+
+	if r.Padding > 0 {
+		result += indent + "└── padded by " + strconv.Itoa(r.Padding) + " empty cell(s)\n"
+	}
+
+	return result
+}
+
+// diff ... print the rows added, removed or changed between two versions of a Rosewood table, or of
+// each table of two directories, optionally writing a redline ODT of the changes, either
+// highlighted or as tracked changes
+func diff(args []string) error {
+
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Println(diffUsageMessage)
+	}
+	inputDir := flags.String("indir", ".", "")
+	redlinePath := flags.String("odt", "", "")
+	trackChanges := flags.Bool("track-changes", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 2 {
+		fmt.Println(diffUsageMessage)
+		return fmt.Errorf("Invalid tables. Please enter the old and the new table, or directory of tables.")
+	}
+
+	// relative paths are taken to be within the input directory
+	oldPath, newPath := flags.Arg(0), flags.Arg(1)
+	if !filepath.IsAbs(oldPath) {
+		oldPath = filepath.Join(*inputDir, oldPath)
+	}
+	if !filepath.IsAbs(newPath) {
+		newPath = filepath.Join(*inputDir, newPath)
+	}
+
+	// pair up the tables to compare, being those of the same name if
+	// given two directories
+	oldPaths := []string{oldPath}
+	newPaths := []string{newPath}
+
+	oldInfo, oldErr := os.Stat(oldPath)
+	newInfo, newErr := os.Stat(newPath)
+	if oldErr == nil && newErr == nil && oldInfo.IsDir() && newInfo.IsDir() {
+
+		oldNames, err := tableFiles(oldPath)
+		if err != nil {
+			return err
+		}
+		newNames, err := tableFiles(newPath)
+		if err != nil {
+			return err
+		}
+
+		oldPaths, newPaths = make([]string, 0), make([]string, 0)
+		for _, name := range mergeNames(oldNames, newNames) {
+
+			if oldNames[name] {
+				oldPaths = append(oldPaths, filepath.Join(oldPath, name))
+			} else {
+				oldPaths = append(oldPaths, "")
+			}
+			if newNames[name] {
+				newPaths = append(newPaths, filepath.Join(newPath, name))
+			} else {
+				newPaths = append(newPaths, "")
+			}
+		}
+	}
+
+	diffs := make([]*rosewood.Diff, 0, len(oldPaths))
+	for i := range oldPaths {
+
+		var oldTable, newTable *rosewood.Table
+		var err error
+
+		if oldPaths[i] != "" {
+			if oldTable, err = readTable(oldPaths[i], nil); err != nil {
+				return fmt.Errorf("%s: %s", oldPaths[i], err)
+			}
+		}
+		if newPaths[i] != "" {
+			if newTable, err = readTable(newPaths[i], nil); err != nil {
+				return fmt.Errorf("%s: %s", newPaths[i], err)
+			}
+		}
+
+		// empty files are left out, being tables in neither version
+		if oldTable == nil && newTable == nil {
+			continue
+		}
+
+		d := rosewood.Compare(oldTable, newTable)
+		diffs = append(diffs, d)
+
+		if !d.Changed() {
+			continue
+		}
+
+		oldName, newName := oldPaths[i], newPaths[i]
+		if oldName == "" {
+			oldName = "/dev/null"
+		}
+		if newName == "" {
+			newName = "/dev/null"
+		}
+		fmt.Printf("--- %s\n+++ %s\n", oldName, newName)
+
+		// tables only in one of the directories are summed up by title
+		switch {
+		case oldTable == nil:
+			fmt.Printf("+ table: %s\n", d.New.Title)
+		case newTable == nil:
+			fmt.Printf("- table: %s\n", d.Old.Title)
+		default:
+			printDiff(d)
+		}
+	}
+
+	if *redlinePath == "" {
+		return nil
+	}
+
+	// the redline document has every table, changed or not, so that it
+	// may be read in place of the new version
+	template, err := odt.ReadTemplate(filepath.Join(DefaultTemplatesDir, "odt_blank_template"))
+	if err != nil {
+		return err
+	}
+
+	odtWriter := odt.NewWriter(template)
+	odtWriter.Theme = TableTheme
+	odtWriter.MaxColumnWidth = MaxColumnWidth
+	odtWriter.TrackChanges = *trackChanges
+	odtWriter.Metadata = odt.Metadata{
+		Title:     "Changes between " + flags.Arg(0) + " and " + flags.Arg(1),
+		Created:   time.Now(),
+		Generator: "scaffolding v" + Version + ", build " + Build,
+	}
+	for i, d := range diffs {
+		redline := d.Redline()
+		redline.Number = i + 1
+		odtWriter.AddTable(redline)
+	}
+
+	outputFile, err := os.Create(*redlinePath)
+	if err != nil {
+		return fmt.Errorf("Unable to create output file: %s", *redlinePath)
+	}
+	defer outputFile.Close()
+
+	_, err = odtWriter.WriteTo(outputFile)
+
+	return err
+}
+
+// printDiff ... print a line per changed title or cell, and per added or removed row
+func printDiff(d *rosewood.Diff) {
+
+	if d.Old.Title != d.New.Title {
+		fmt.Printf("~ title: %s --> %s\n", d.Old.Title, d.New.Title)
+	}
+
+	for _, row := range d.Rows {
+
+		label := row.Label
+		if row.Header {
+			label = strings.TrimSpace("header " + row.Label)
+		}
+
+		switch row.Change {
+		case rosewood.ChangeAdded:
+			fmt.Printf("+ %s: %s\n", label, rowText(row.New))
+		case rosewood.ChangeRemoved:
+			fmt.Printf("- %s: %s\n", label, rowText(row.Old))
+		case rosewood.ChangeChanged:
+			for _, j := range row.Columns {
+
+				// cells are named after their column heading in the last
+				// header row, if any
+				column := "column " + strconv.Itoa(j+1)
+				if len(d.New.Headers) > 0 && !row.Header {
+					headings := d.New.Headers[len(d.New.Headers)-1]
+					if j < len(headings) && headings[j].Text != "" {
+						column = headings[j].Text
+					}
+				}
+
+				oldText, newText := "", ""
+				if j < len(row.Old) {
+					oldText = row.Old[j].Text
+				}
+				if j < len(row.New) {
+					newText = row.New[j].Text
+				}
+
+				fmt.Printf("~ %s [%s]: %s --> %s\n", label, column, oldText, newText)
+			}
+		}
+	}
+}
+
+// tableFiles ... the names of the files of a directory of tables, excluding hidden files
+func tableFiles(dir string) (map[string]bool, error) {
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	for _, f := range files {
+		if !f.IsDir() && !strings.HasPrefix(f.Name(), ".") {
+			names[f.Name()] = true
+		}
+	}
+
+	return names, nil
+}
+
+// mergeNames ... the names of either set, in alphabetical order
+func mergeNames(a map[string]bool, b map[string]bool) []string {
+
+	names := make([]string, 0, len(a)+len(b))
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if !a[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// listTemplates ... print the names of the ODT templates available
+func listTemplates(args []string) error {
+
+	flags := flag.NewFlagSet("templates", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Println(templatesUsageMessage)
+	}
+	dir := flags.String("dir", DefaultTemplatesDir, "")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	files, err := ioutil.ReadDir(*dir)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+
+		// only list the files which are usable as templates
+		if f.IsDir() {
+			continue
+		}
+		if _, err := odt.ReadTemplate(filepath.Join(*dir, f.Name())); err != nil {
+			continue
+		}
+
+		if f.Name() == "odt_blank_template" {
+			fmt.Printf("%s (default)\n", f.Name())
+		} else {
+			fmt.Println(f.Name())
+		}
+	}
+
+	return nil
+}
+
+// help ... print the usage message of the program, or of the given command
+func help(args []string) error {
+
+	messages := map[string]string{
+		"convert":   usageMessage,
+		"validate":  validateUsageMessage,
+		"inspect":   inspectUsageMessage,
+		"diff":      diffUsageMessage,
+		"serve":     serveUsageMessage,
+		"templates": templatesUsageMessage,
+	}
+
+	message := usageMessage
+	if len(args) > 0 {
+		if commandMessage, ok := messages[args[0]]; ok {
+			message = commandMessage
+		}
+	}
+
+	fmt.Println(message)
+
+	return nil
+}
+
+// rowText ... the cells of a row separated by "|", with the first cell keeping its indentation
+func rowText(cells []rosewood.Cell) string {
+
+	pieces := make([]string, 0, len(cells))
+	for _, cell := range cells {
+		pieces = append(pieces, strings.Repeat(rosewood.IndentUnit, cell.Indent)+cell.Text)
+	}
+
+	return strings.Join(pieces, " | ")
+}
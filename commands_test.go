@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rbisewski/scaffolding/rosewood"
+)
+
+func TestNewInspection(t *testing.T) {
+	tests := []struct {
+		name       string
+		contents   string
+		columns    int
+		headerRows int
+		padding    []int
+		wants      []string
+	}{
+		{"padded row", "Padded\n---\nA | B | C\nx | 1.2\ny | 3 | 4\n", 3, 1, []int{1, 0}, []string{
+			"├── title: Padded\n",
+			"    ├── row 1\n    │   ├── \"x\" text\n    │   ├── \"1.2\" float 1.2\n    │   └── padded by 1 empty cell(s)\n",
+			"        └── \"4\" float 4\n",
+		}},
+		{"indented cell", "Indented\n---\nOutcome | n\nDeath | 12\n  Stroke | 3\n", 2, 1, []int{0, 0}, []string{
+			"│       ├── \"Outcome\" text, bold\n",
+			"        ├── \"Stroke\" text, indent 1\n",
+		}},
+		{"json spanning cell", `{"title": "Outcomes by arm",
+			"headers": [[{"text": "Outcome"}, {"text": "Treatment"}, {"text": "Placebo"}], [{"text": ""}, {"text": "n (%)", "span": 2}]],
+			"rows": [[{"text": "Death"}, {"text": "12 (4%)"}, {"text": "20 (7%)"}], [{"text": "Not reported", "span": 3}]]}`,
+			3, 2, []int{0, 0}, []string{
+				"├── header rows: 2\n│   ├── row 1\n",
+				"│   └── row 2\n│       ├── \"\" empty, bold\n│       └── \"n (%)\" text, bold, spans 2 columns\n",
+				"    │   ├── \"12 (4%)\" interval\n",
+				"        └── \"Not reported\" text, spans 3 columns\n",
+			}},
+		{"empty file", "", 0, 0, nil, []string{"table (testing/table)\n└── empty\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// empty files give a nil table, as readTable does
+			var table *rosewood.Table
+			if tt.contents != "" {
+				var err error
+				if table, err = parseTable([]byte(tt.contents)); err != nil {
+					t.Fatalf("parseTable() error = %v", err)
+				}
+			}
+
+			got := newInspection("table", "testing/table", table)
+			if got.Columns != tt.columns || got.HeaderRows != tt.headerRows || len(got.Rows) != len(tt.padding) {
+				t.Errorf("newInspection() = %d columns, %d header rows, %d rows, want %d, %d and %d",
+					got.Columns, got.HeaderRows, len(got.Rows), tt.columns, tt.headerRows, len(tt.padding))
+			}
+			for r, row := range got.Rows {
+				if r < len(tt.padding) && row.Padding != tt.padding[r] {
+					t.Errorf("newInspection() row %d padding = %d, want %d", r+1, row.Padding, tt.padding[r])
+				}
+			}
+
+			tree := got.tree()
+			for _, want := range tt.wants {
+				if !strings.Contains(tree, want) {
+					t.Errorf("tree() = %q, lacks %q", tree, want)
+				}
+			}
+		})
+	}
+}
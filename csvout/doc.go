@@ -0,0 +1,6 @@
+/*
+Package csvout writes Rosewood tables as plain-text CSV.
+
+	_, err := csvout.NewWriter().AddTable(table).WriteTo(file)
+*/
+package csvout
@@ -0,0 +1,142 @@
+package csvout
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rbisewski/scaffolding/rosewood"
+)
+
+// Writer ... assembles Rosewood tables into plain-text CSV
+type Writer struct {
+
+	// caption template of every table, e.g. "Table {n}: {title}"
+	Caption string
+
+	// whether to follow each table with a note of its source file, and
+	// the program named in that note as having converted it
+	Provenance bool
+	Generator  string
+
+	entries []entry
+	err     error
+}
+
+// entry ... a table added to the output, or a placeholder added in place of one
+type entry struct {
+	table       *rosewood.Table
+	placeholder string
+}
+
+// NewWriter ... pass back a new CSV writer with the default settings
+func NewWriter() *Writer {
+	return &Writer{
+		Caption: "Table {n}: {title}",
+	}
+}
+
+// AddTable ... append a table to the output; any error is passed back by WriteTo
+func (w *Writer) AddTable(t *rosewood.Table) *Writer {
+
+	if t == nil {
+		w.err = fmt.Errorf("AddTable() --> invalid input")
+		return w
+	}
+
+	w.entries = append(w.entries, entry{table: t})
+
+	return w
+}
+
+// AddPlaceholder ... append a line of text in place of a table, e.g. of one that failed to convert
+func (w *Writer) AddPlaceholder(text string) *Writer {
+
+	w.entries = append(w.entries, entry{placeholder: text})
+
+	return w
+}
+
+// WriteTo ... write every table as a caption line followed by a line per row, with a blank line
+// between tables
+func (w *Writer) WriteTo(out io.Writer) (int64, error) {
+
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	if out == nil {
+		return 0, fmt.Errorf("WriteTo() --> invalid input")
+	}
+
+	// each line is written as it is converted, with the bytes reaching
+	// the underlying writer being counted
+	counter := &countingWriter{writer: out}
+	buffered := bufio.NewWriter(counter)
+
+	for i, e := range w.entries {
+
+		if i > 0 {
+			buffered.WriteString("\n")
+		}
+
+		if e.table == nil {
+			buffered.WriteString(e.placeholder + "\n")
+			continue
+		}
+
+		t := e.table
+		buffered.WriteString(t.Caption(w.Caption) + "\n")
+
+		for _, cells := range t.Headers {
+			buffered.WriteString(row(cells) + "\n")
+		}
+		for _, cells := range t.Rows {
+			buffered.WriteString(row(cells) + "\n")
+		}
+
+		// follow the table with a note of where it came from, if requested
+		if w.Provenance && t.Source != nil {
+			note := t.Source.String()
+			if w.Generator != "" {
+				note += "; converted by " + w.Generator
+			}
+			buffered.WriteString(note + "\n")
+		}
+	}
+
+	// any error writing is kept by the buffer until it is flushed
+	err := buffered.Flush()
+
+	return counter.count, err
+}
+
+// row ... join the cells of a row with commas, after removing any commas within them; the first
+// cell keeps its indentation, and cells spanning several columns are followed by empty ones
+func row(cells []rosewood.Cell) string {
+
+	pieces := make([]string, 0, len(cells))
+	for _, cell := range cells {
+		pieces = append(pieces, strings.Repeat(rosewood.IndentUnit, cell.Indent)+
+			strings.Replace(cell.Text, ",", " ", -1))
+		for c := 1; c < cell.Spanned(); c++ {
+			pieces = append(pieces, "")
+		}
+	}
+
+	return strings.Join(pieces, ",")
+}
+
+// countingWriter ... tally the bytes passed on to the underlying writer
+type countingWriter struct {
+	writer io.Writer
+	count  int64
+}
+
+// Write ... pass the given bytes on, counting them
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.writer.Write(p)
+	c.count += int64(n)
+	return n, err
+}
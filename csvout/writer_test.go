@@ -0,0 +1,51 @@
+package csvout
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rbisewski/scaffolding/rosewood"
+)
+
+func TestWriteTo(t *testing.T) {
+	table, err := rosewood.Parse(strings.NewReader("Outcomes\n---\nOutcome | n\nDeath, any cause | 12\n  Stroke | 3\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	table.Number = 1
+
+	tests := []struct {
+		name         string
+		tables       int
+		placeholders int
+		want         string
+	}{
+		{"no tables", 0, 0, ""},
+		{"one table", 1, 0, "Table 1: Outcomes\nOutcome,n\nDeath  any cause,12\n  Stroke,3\n"},
+		{"placeholder", 1, 1, "Table 1: Outcomes\nOutcome,n\nDeath  any cause,12\n  Stroke,3\n\nfailed\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := NewWriter()
+			for i := 0; i < tt.tables; i++ {
+				writer.AddTable(table)
+			}
+			for i := 0; i < tt.placeholders; i++ {
+				writer.AddPlaceholder("failed")
+			}
+
+			var output bytes.Buffer
+			n, err := writer.WriteTo(&output)
+			if err != nil {
+				t.Fatalf("WriteTo() error = %v", err)
+			}
+			if output.String() != tt.want {
+				t.Errorf("WriteTo() wrote %q, want %q", output.String(), tt.want)
+			}
+			if n != int64(output.Len()) {
+				t.Errorf("WriteTo() = %d, wrote %d bytes", n, output.Len())
+			}
+		})
+	}
+}
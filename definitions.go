@@ -1,7 +1,11 @@
 package main
 
 import (
-	"archive/zip"
+	"sync"
+	"time"
+
+	"github.com/rbisewski/scaffolding/odt"
+	"github.com/rbisewski/scaffolding/rosewood"
 )
 
 // Config holds user-provided and other settings
@@ -15,20 +19,127 @@ type Config struct {
 
 	// location to write the converted output
 	outputDir string
+
+	// number of tables to read and parse concurrently
+	jobs int
+
+	// whether to replace tables that fail to convert with a placeholder,
+	// rather than stopping
+	keepGoing bool
+
+	// whether to rebuild the output whenever the tables change
+	watch bool
+
+	// CSV list of per-table column alignments
+	alignments string
+
+	// CSV list of per-table column widths
+	widths string
+
+	// CSV list of tables to place on landscape pages
+	landscape string
+
+	// name of a journal style preset
+	preset string
+
+	// location of a JSON theme file
+	themeFile string
+
+	// caption template of every table
+	caption string
+
+	// number given to the first table
+	startNumber int
+
+	// CSV list of per-table number overrides
+	numbers string
+
+	// document title, author, subject and CSV list of keywords
+	title    string
+	author   string
+	subject  string
+	keywords string
+}
+
+// Report ... the tables to convert and how to convert them, as given by the program arguments
+type Report struct {
+	config *Config
+
+	// names of the tables and the paths of their files
+	tables []string
+	paths  []string
+
+	// per-table column alignments, widths, landscape pages and numbers
+	alignments map[string]string
+	widths     map[string]string
+	landscape  map[string]bool
+	numbers    map[string]int
+
+	// caption template of every table, if not the one suited to the theme
+	caption string
+
+	// blank ODT the document is based on, if not printing CSV
+	template *odt.Template
+
+	// parsed tables, reused for as long as their files are unchanged
+	cache *tableCache
+}
+
+// tableCache ... parsed tables keyed by the path of their file
+type tableCache struct {
+	sync.Mutex
+	entries map[string]cachedTable
+}
+
+// cachedTable ... a parsed table, and the modification time and size of its file when parsed
+type cachedTable struct {
+	modified time.Time
+	size     int64
+	table    *rosewood.Table
+}
+
+// Server ... the HTTP conversion service
+type Server struct {
+	sync.Mutex
+
+	// largest request body accepted, in bytes
+	maxSize int64
+
+	// ODT templates, keyed by name, which have been read in so far
+	templates map[string]*odt.Template
+}
+
+// Validation ... the problems the validate command found in a table
+type Validation struct {
+	Table    string             `json:"table"`
+	Path     string             `json:"path"`
+	Valid    bool               `json:"valid"`
+	Problems []rosewood.Problem `json:"problems"`
+}
+
+// Inspection ... the parsed structure of a table, as printed by the inspect command
+type Inspection struct {
+	Table      string         `json:"table"`
+	Path       string         `json:"path"`
+	Title      string         `json:"title"`
+	Columns    int            `json:"columns"`
+	HeaderRows int            `json:"header_rows"`
+	Headers    []InspectedRow `json:"headers,omitempty"`
+	Rows       []InspectedRow `json:"rows"`
 }
 
-// Odt ... Structure for handling ODT files
-type Odt struct {
-	files    []*zip.File
-	content  string
-	settings string
-	styles   string
+// InspectedRow ... the cells of a row, and how many empty cells pad it out to the width of the table
+type InspectedRow struct {
+	Cells   []InspectedCell `json:"cells"`
+	Padding int             `json:"padding,omitempty"`
 }
 
-// CachedOdtTemplate ... structure for handling ODT files content replacement
-type CachedOdtTemplate struct {
-	zipReader *zip.ReadCloser
-	content   string
-	settings  string
-	styles    string
+// InspectedCell ... a cell and how its contents were recognised, e.g. as a percentage
+type InspectedCell struct {
+	Text   string   `json:"text"`
+	Indent int      `json:"indent"`
+	Bold   bool     `json:"bold"`
+	Span   int      `json:"span"`
+	Type   string   `json:"type"`
+	Value  *float64 `json:"value,omitempty"`
 }
@@ -3,18 +3,124 @@ package main
 /*
  * Convert rosewood tables into ISO standard ODT files or CSV files.
  *
- * Usage: identify_conditions
+ * Commands:
+ * 	convert       Converts tables into an ODT, CSV or JSON file (default)
+ * 	validate      Checks tables for problems, such as ragged rows
+ * 	inspect       Prints the parsed structure of tables
+ * 	diff          Prints the rows which changed between two versions of tables
+ * 	serve         Serves table conversions over HTTP
+ * 	templates     Lists the available ODT templates
+ * 	help          Prints the usage message of a command; e.g. "help diff"
+ *
+ * Usage: identify_conditions [convert]
  *        -csv
+ *        -format <odt|csv|json>
+ *        -index
+ *        -title-page
+ *        -provenance
  *        -tables <comma,separated,list,of,tables>
  *        -indir  <path_to_input_directory>
  *        -outdir <path_to_output_directory>
+ *        -jobs <n>
+ *        -keep-going
+ *        -watch
+ *        -align  <table=alignments,...>
+ *        -widths <table=cm:cm:...,...>
+ *        -max-col-width <cm>
+ *        -landscape <comma,separated,list,of,tables>
+ *        -preset <apa|ama|nejm>
+ *        -theme  <path_to_theme_file>
+ *        -caption <caption_template>
+ *        -start-number <n>
+ *        -numbers <table=n,...>
+ *        -title <document_title> -author <name> -subject <subject>
+ *        -keywords <comma,separated,list,of,keywords>
+ *        -header <left|centre|right> -footer <left|centre|right>
  *
  * Arguments:
  * 	h, help       Prints this usage message
  *   	version       Prints the current program version and build info
  *	csv           Prints the given rosewood tables as plain-text CSVs (default is ODT)
+ *	format        Output format: odt, csv or json (default odt); JSON gives the title,
+ *	              number, header rows, rows of cells with their text, indent, bold
+ *	              and span, footnotes and source file of each table
+ * 	index         Begins the ODT with a list of tables and their page numbers
+ * 	title-page    Begins the ODT with a title page of the title, subject, author and date
+ * 	provenance    Follows each table with its source file path, SHA-256, modification time
+ * 	              and the version of scaffolding that converted it
  * 	tables        Comma separated list of tables; e.g. "table-w-conditions,table-wo-screening"
+ * 	              Each may be a Rosewood table or a single table in the JSON form of
+ * 	              -format json, which may have several header rows
  * 	outdir        Output location; e.g. /path/to/output/directory
+ * 	jobs          Number of tables to read and parse at once (default is the number of CPUs)
+ * 	keep-going    Replaces tables that fail to convert with a note of the error, then lists
+ * 	              the failures and exits with code 1 once the output is written
+ * 	watch         Keeps running, rebuilding the output a second after any of the tables
+ * 	              change; unchanged tables are not parsed again
+ * 	align         Per-table column alignments of l(eft), c(entre), r(ight), d(ecimal),
+ * 	              p(arenthesis) or a(uto); e.g. "table-w-conditions=lddp"
+ * 	widths        Per-table colon separated column widths in cm, blank or a(uto) to
+ * 	              estimate from the cell text; e.g. "table-w-conditions=5.5:a:3"
+ * 	max-col-width Widest estimated column width in cm (default 6)
+ * 	landscape     Tables to place on landscape pages; tables too wide for a portrait
+ * 	              page are placed on landscape pages regardless
+ * 	preset        Journal table style: apa, ama or nejm; e.g. three-line rules
+ * 	theme         JSON file of title_font, title_colour, title_size, body_font, body_size,
+ * 	              borders (grid, three-line, horizontal or none), border_line,
+ * 	              cell_padding, header_shading, zebra_striping and caption (inline,
+ * 	              label-bold or stacked) settings, applied on top of any preset
+ * 	caption       Caption of every table; e.g. "Supplementary Table S{n}. {title}"
+ * 	start-number  Number of the first table (default 1)
+ * 	numbers       Per-table numbers, which later tables continue from; e.g. "table-w-conditions=7"
+ * 	title         Document title of the ODT
+ * 	author        Document author of the ODT
+ * 	subject       Document subject of the ODT
+ * 	keywords      Comma separated list of document keywords of the ODT
+ * 	header        Page header of "left|centre|right" sections, which may contain {report},
+ * 	              {date}, {page}, {pages} and {source} (the current table file);
+ * 	              e.g. "{report}||CONFIDENTIAL"
+ * 	footer        Page footer in the same form as the header (default "||{page}");
+ * 	              e.g. "{source}|{date}|Page {page} of {pages}"
+ *
+ * Usage: identify_conditions serve
+ *        -addr <host:port>
+ *        -max-size <bytes>
+ *
+ * 	Serves POST /convert, converting the Rosewood table of the request body,
+ * 	or each Rosewood file of a multipart/form-data upload, into the format of
+ * 	the ?format=odt|csv|json query parameter or the Accept header. The ?template
+ * 	and ?preset parameters select an ODT template and journal table style.
+ * 	Requests larger than -max-size bytes (default 10485760) are refused.
+ *
+ * Usage: identify_conditions validate [-json] -tables <list> -indir <path> -jobs <n>
+ *
+ * 	Prints "ok" or the problems of each table, as JSON if -json is given, exiting
+ * 	with code 1 if any are invalid. Rows with a different number of columns to the
+ * 	header, missing or duplicate titles, bytes which are not UTF-8 and footnote
+ * 	markers, such as ^a, without a footnote line are errors; footnotes which are
+ * 	never marked, cells with commas and trailing whitespace are warnings.
+ *
+ * Usage: identify_conditions inspect [-json] -tables <list> -indir <path> [path ...]
+ *
+ * 	Prints the title, number of columns and header rows, then each cell of each
+ * 	row along with its indentation level, emphasis and recognised value type,
+ * 	as a tree or, if -json is given, as JSON.
+ *
+ * Usage: identify_conditions diff -indir <path> -odt <path> -track-changes <old> <new>
+ *
+ * 	Compares two versions of a table, or the tables of the same name in two
+ * 	directories, matching rows by their first cell along with those of the rows
+ * 	they are nested under. Added rows are printed with "+", removed rows with
+ * 	"-" and changed cells with "~". If -odt is given, the new tables are also
+ * 	written to an ODT file with the added, removed and changed cells highlighted,
+ * 	or, if -track-changes is also given, as tracked changes which reviewers may
+ * 	accept or reject cell by cell. Rows themselves are not tracked, so rejecting
+ * 	every change leaves an empty row in place of each added row, and accepting
+ * 	every change one in place of each removed row.
+ *
+ * Usage: identify_conditions templates -dir <path>
+ *
+ * 	Lists the ODT templates of the templates directory (default "templates").
  *
  * 	Description:
  * 		The ODT values created by this program can be read by Libreoffice or
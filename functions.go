@@ -1,16 +1,22 @@
 package main
 
 import (
-	"archive/zip"
 	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/rbisewski/scaffolding/csvout"
+	"github.com/rbisewski/scaffolding/jsonout"
+	"github.com/rbisewski/scaffolding/odt"
+	"github.com/rbisewski/scaffolding/rosewood"
 )
 
 // Fatal prints error message in red and exits to shell with code 1
@@ -19,521 +25,405 @@ func fatal(err error) {
 	os.Exit(1)
 }
 
-// convertRosewoodToCSV ... turns an array of lines of a Rosewood table into CSV
-func convertRosewoodToCSV(lines []string, num int) (string, error) {
+// newReport ... gather the tables to convert, and how, from the program arguments
+func newReport(config *Config) (*Report, error) {
 
-	if len(lines) < 1 {
-		return "", fmt.Errorf("convertRosewoodToCSV() --> invalid input")
+	if config == nil {
+		return nil, fmt.Errorf("newReport() --> invalid input")
 	}
 
-	titleHasNotBeenPrinted := true
-	headerHasBeenPrinted := false
-	rowNum := 1
-	result := ""
-
-	// attempt to obtain the number of columns of the table
-	maxLength := 0
-	for _, l := range lines {
-		pieces := strings.Split(l, "|")
-		if len(pieces) > maxLength {
-			maxLength = len(pieces) - 1
-		}
+	report := &Report{
+		config:    config,
+		landscape: make(map[string]bool),
+		numbers:   make(map[string]int),
+		cache:     &tableCache{entries: make(map[string]cachedTable)},
 	}
-	if maxLength < 1 {
-		return "", fmt.Errorf("convertRosewoodToCSV() --> empty table given")
-	}
-	lengthAsString := strconv.Itoa(maxLength)
-
-	// for every line in the table...
-	maxColumns := 0
-	maxRows := 0
-	for _, l := range lines {
-
-		// set a table number
-		numAsStr := strconv.Itoa(num)
-
-		trimmedLine := strings.TrimSpace(l)
-
-		if trimmedLine == "" {
-			continue
-		}
-
-		if trimmedLine == "---" {
-			continue
-		}
-
-		if titleHasNotBeenPrinted {
-			result += SCAFFOLDING_TABLE_TITLE_START
-			result += "Table " + numAsStr + ": " + trimmedLine + "\n"
-			result += SCAFFOLDING_TABLE_TITLE_END
-			result += SCAFFOLDING_TABLE_START + numAsStr + ":\n"
-			result += SCAFFOLDING_COLUMN_TABLE + numAsStr + ".A-len-" + lengthAsString + ":\n"
-			titleHasNotBeenPrinted = false
-			continue
-		} else if !headerHasBeenPrinted {
-			headerHasBeenPrinted = true
-		} else {
-			rowNum = 2
-		}
-
-		// generate a row number, for purposes of styling
-		rowNumAsString := strconv.Itoa(rowNum)
-
-		// Rosewood instructions are exactly one piece, so check for 2+
-		pieces := strings.Split(l, "|")
-		if len(pieces) < 2 {
-			continue
-		}
 
-		// set a starting letter, ISO standard suggests A
-		startingLetter := 65
-
-		cleanedLine := ""
-		for i, p := range pieces {
-
-			// sometimes for the first element, there is a
-			// Rosewood "  " prefix, so this needs to re-append
-			// it to the trimmed string
-			prefix := ""
-			if i == 0 && strings.HasPrefix(p, "  ") && PrintAsCSV {
-				prefix = "  "
-			} else if i == 0 && strings.HasPrefix(p, "  ") {
-				prefix = SCAFFOLDING_ODT_SPACE
-			}
-
-			cleanedString := strings.TrimSpace(p)
-
-			if strings.Contains(cleanedString, ",") {
-				cleanedString = strings.Replace(cleanedString, ",", " ", -1)
-			}
-
-			letterStr := string(byte(startingLetter))
-
-			// determine whether to centre the cell contents or not; current logic centres all elements after 1st
-			cellStartStyle := SCAFFOLDING_CELL_CENTRED_START_TABLE + numAsStr + "." + letterStr + rowNumAsString + ":"
-			if i == 0 {
-				cellStartStyle = SCAFFOLDING_CELL_START_TABLE + numAsStr + "." + letterStr + rowNumAsString + ":"
-			}
-
-			// add a bold style to the first row of every table
-			if rowNum == 1 {
-				cellStartStyle += SCAFFOLDING_CELL_BOLD
-			}
-
-			if cleanedString == "" {
-				cleanedLine += cellStartStyle + " " + SCAFFOLDING_CELL_END + SCAFFOLDING_COVERED_CELL_END
-			} else if i == 0 {
-				cleanedLine = cellStartStyle + prefix + cleanedString + SCAFFOLDING_CELL_END + " "
-			} else {
-				cleanedLine += cellStartStyle + cleanedString + SCAFFOLDING_CELL_END + " "
-			}
-
-			startingLetter++
-
-			if i > maxColumns {
-				maxColumns = i
-			}
-		}
-
-		result += SCAFFOLDING_ROW_START + cleanedLine + SCAFFOLDING_ROW_END + "\n"
-
-		maxRows++
-	}
+	report.tables, report.paths = splitTables(config)
 
-	maxColumnsAsString := strconv.Itoa(maxColumns + 1)
-	maxRowsAsString := strconv.Itoa(maxRows)
-
-	result += SCAFFOLDING_TABLE_COLS + maxColumnsAsString + "-rows-" + maxRowsAsString + ":\n"
-	result += SCAFFOLDING_TABLE_END + "\n"
-
-	return result, nil
-}
-
-// ReadOdtFile ... read contents of Odt file
-func ReadOdtFile(templateName string) (*CachedOdtTemplate, error) {
-
-	if templateName == "" {
-		return nil, fmt.Errorf("ReadOdtFile() --> invalid input")
-	}
-
-	path := filepath.Join(DefaultTemplatesDir, templateName)
-
-	//
-	// decompress the ODT file as it is in Zip format
-	//
-	reader, err := zip.OpenReader(path)
+	// split the alignment and width lists into table --> column maps
+	var err error
+	report.alignments, err = parseTableOptions(config.alignments)
 	if err != nil {
 		return nil, err
 	}
-
-	//
-	// obtain the strings from content.xml, settings.xml, and styles.xml
-	//
-
-	content, err := readFile(reader.File, "content.xml")
+	report.widths, err = parseTableOptions(config.widths)
 	if err != nil {
 		return nil, err
 	}
 
-	settings, err := readFile(reader.File, "settings.xml")
-	if err != nil {
-		return nil, err
+	// check them now, rather than once the output has been started
+	for t, alignment := range report.alignments {
+		if err = (odt.TableOptions{Alignments: alignment}).Check(); err != nil {
+			return nil, fmt.Errorf("Invalid alignments of %s: %s. Please use the letters a, l, c, r, d or p.", t, alignment)
+		}
 	}
-
-	styles, err := readFile(reader.File, "styles.xml")
-	if err != nil {
-		return nil, err
+	for t, widths := range report.widths {
+		if err = (odt.TableOptions{Widths: widths}).Check(); err != nil {
+			return nil, fmt.Errorf("Invalid column widths of %s: %s. Please use widths in cm, e.g. 4.5:a:2cm.", t, widths)
+		}
 	}
 
-	return &CachedOdtTemplate{zipReader: reader, content: content, settings: settings, styles: styles}, nil
-}
-
-// New ... pass back an new editable instance of the ODT file
-func (r *CachedOdtTemplate) New() *Odt {
-	return &Odt{
-		files:    r.zipReader.File,
-		content:  r.content,
-		settings: r.settings,
-		styles:   r.styles,
+	// tables which ought to always be placed on landscape pages
+	for _, t := range strings.Split(config.landscape, ",") {
+		report.landscape[strings.TrimSpace(t)] = true
 	}
-}
 
-// readContent ... open content.xml from the cached ODT file
-func readFile(files []*zip.File, filename string) (string, error) {
-
-	if files == nil || len(files) == 0 || filename == "" {
-		return "", fmt.Errorf("readContentFile --> invalid input")
+	// per-table caption number overrides
+	numbers, err := parseTableOptions(config.numbers)
+	if err != nil {
+		return nil, err
 	}
-
-	var fileOfInterest *zip.File
-	var documentReader io.ReadCloser
-
-	for _, f := range files {
-		if f.Name == filename {
-			fileOfInterest = f
-			break
+	for t, number := range numbers {
+		report.numbers[t], err = strconv.Atoi(number)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid table number: %s. Please enter a whole number.", number)
 		}
 	}
 
-	if fileOfInterest == nil {
-		return "", fmt.Errorf("readContentFile --> content.xml not found")
+	// if no caption template was given, the one suited to the theme is
+	// used
+	report.caption = config.caption
+	if report.caption != "" && !strings.Contains(report.caption, "{title}") {
+		return nil, fmt.Errorf("Invalid caption template: %s. Please include a {title}.", report.caption)
 	}
 
-	documentReader, err := fileOfInterest.Open()
-	if err != nil {
-		return "", err
-	}
-
-	bytes, err := ioutil.ReadAll(documentReader)
-	if err != nil {
-		return "", err
+	if OutputFormat == "odt" {
+		report.template, err = odt.ReadTemplate(filepath.Join(DefaultTemplatesDir, "odt_blank_template"))
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return string(bytes), nil
+	return report, nil
 }
 
-// AppendStrings ... attach plain-text word to the document in question
-func (odt *Odt) AppendStrings(data string) error {
+// splitTables ... split the table list into its names and the paths of their files
+func splitTables(config *Config) ([]string, []string) {
 
-	// no data means nothing to do
-	if data == "" {
-		return nil
-	}
+	names := strings.Split(config.tables, ",")
 
-	//
-	// Extract styles from the scaffolding elements
-	//
-
-	extractedStyles, err := obtainStylesFromScaffolding(data)
-	if err != nil {
-		return err
+	paths := make([]string, 0, len(names))
+	for _, t := range names {
+		paths = append(paths, filepath.Join(config.inputDir, t))
 	}
 
-	//
-	// Append the new document styles
-	//
-
-	newContentXML := strings.Replace(odt.content, "<office:automatic-styles/>",
-		"<office:automatic-styles>"+
-
-			"<style:style style:name=\"P1\" style:family=\"paragraph\" style:parent-style-name=\"Standard\">"+
-			"<style:paragraph-properties fo:break-before=\"page\" />"+
-			"<style:text-properties fo:color=\"#566cc9\" fo:font-size=\"11pt\" />"+
-			"</style:style>"+
-
-			"<style:style style:name=\"P2\" style:family=\"paragraph\" style:parent-style-name=\"Footer\">"+
-			"<style:paragraph-properties fo:text-align=\"end\" style:justify-single-word=\"false\"/>"+
-			"</style:style>"+
-
-			"<style:style style:name=\"P3\" style:family=\"paragraph\" style:parent-style-name=\"Standard\">"+
-			"<style:paragraph-properties fo:text-align=\"center\" style:justify-single-word=\"false\"/>"+
-			"</style:style>"+
+	return names, paths
+}
 
-			"<style:style style:name=\"P4\" style:family=\"paragraph\" style:parent-style-name=\"Standard\">"+
-			"<style:text-properties fo:font-weight=\"bold\" style:font-weight-asian=\"bold\" style:font-weight-complex=\"bold\"/>"+
-			"</style:style>"+
+// write ... convert the tables of the report into a CSV, JSON or ODT file at the given path
+func (r *Report) write(outputPath string) error {
 
-			"<style:style style:name=\"P5\" style:family=\"paragraph\" style:parent-style-name=\"Standard\">"+
-			"<style:paragraph-properties fo:text-align=\"center\" style:justify-single-word=\"false\"/>"+
-			"<style:text-properties fo:font-weight=\"bold\" style:font-weight-asian=\"bold\" style:font-weight-complex=\"bold\"/>"+
-			"</style:style>"+
+	// the program named in the document properties and provenance notes
+	generator := "scaffolding v" + Version + ", build " + Build
 
-			extractedStyles+
+	csvWriter := csvout.NewWriter()
+	csvWriter.Caption = odt.CaptionTemplate(TableTheme)
+	if r.caption != "" {
+		csvWriter.Caption = r.caption
+	}
+	csvWriter.Provenance = PrintProvenance
+	csvWriter.Generator = generator
 
-			"</office:automatic-styles>", -1)
+	jsonWriter := jsonout.NewWriter()
+	jsonWriter.Generator = generator
 
-	// replace the old content.xml with the newly generated content
-	odt.content = newContentXML
-	newContentXML = ""
+	var odtWriter *odt.Writer
+	if OutputFormat == "odt" {
 
-	//
-	// Append the new footer styles
-	//
+		keywords := make([]string, 0)
+		for _, k := range strings.Split(r.config.keywords, ",") {
+			if strings.TrimSpace(k) != "" {
+				keywords = append(keywords, strings.TrimSpace(k))
+			}
+		}
 
-	newStylesXML := strings.Replace(odt.styles, "</style:style><text:outline-style style:name=\"Outline\">",
-		"</style:style>"+
-			"<style:style style:name=\"Footer\" style:family=\"paragraph\" style:parent-style-name=\"Standard\" style:class=\"extra\">"+
-			"<style:paragraph-properties text:number-lines=\"false\" text:line-number=\"0\">"+
-			"<style:tab-stops>"+
-			"<style:tab-stop style:position=\"8.795cm\" style:type=\"center\"/>"+
-			"<style:tab-stop style:position=\"17.59cm\" style:type=\"right\"/>"+
-			"</style:tab-stops>"+
-			"</style:paragraph-properties>"+
-			"</style:style>"+
-			"<text:outline-style style:name=\"Outline\">", -1)
+		odtWriter = odt.NewWriter(r.template)
+		odtWriter.Theme = TableTheme
+		odtWriter.Caption = r.caption
+		odtWriter.Header = PageHeader
+		odtWriter.Footer = PageFooter
+		odtWriter.TitlePage = PrintTitlePage
+		odtWriter.Index = PrintTableIndex
+		odtWriter.IndexTitle = TableIndexTitle
+		odtWriter.IndexEntriesPerPage = TableIndexEntriesPerPage
+		odtWriter.Provenance = PrintProvenance
+		odtWriter.MaxColumnWidth = MaxColumnWidth
+		odtWriter.Metadata = odt.Metadata{
+			Title:     r.config.title,
+			Author:    r.config.author,
+			Subject:   r.config.subject,
+			Keywords:  keywords,
+			Created:   time.Now(),
+			Generator: generator,
+		}
+	}
 
-	newStylesXML = strings.Replace(newStylesXML, "<office:automatic-styles><style:page-layout style:name=\"Mpm1\">",
-		"<office:automatic-styles><style:style style:name=\"MP1\" style:family=\"paragraph\" style:parent-style-name=\"Footer\">"+
-			"<style:paragraph-properties fo:text-align=\"end\" style:justify-single-word=\"false\"/>"+
-			"</style:style><style:page-layout style:name=\"Mpm1\">", -1)
+	// read and parse every file concurrently, reporting every table
+	// that could not be converted rather than just the first
+	parsedTables, errs := readTables(r.paths, r.config.jobs, r.cache)
 
-	newStylesXML = strings.Replace(newStylesXML, "<style:footer-style/>",
-		"<style:footer-style>"+
-			"<style:header-footer-properties fo:min-height=\"0cm\" fo:margin-top=\"0.499cm\"/>"+
-			"</style:footer-style>", -1)
+	failures := ""
+	failureCount := 0
+	for i, err := range errs {
+		if err != nil {
+			failures += "\n  " + r.tables[i] + ": " + err.Error()
+			failureCount++
+		}
+	}
+	if failureCount > 0 && !r.config.keepGoing {
+		return fmt.Errorf("Unable to convert %d of %d tables:%s", failureCount, len(r.tables), failures)
+	}
 
-	newStylesXML = strings.Replace(newStylesXML, "<style:master-page style:name=\"Standard\" style:page-layout-name=\"Mpm1\"/>",
-		"<style:master-page style:name=\"Standard\" style:page-layout-name=\"Mpm1\">"+
-			"<style:footer>"+
-			"<text:p text:style-name=\"MP1\">"+
-			"<text:page-number text:select-page=\"current\">1</text:page-number>"+
-			"</text:p>"+
-			"</style:footer>"+
-			"</style:master-page>", -1)
+	// number and add the tables in their original order
+	tableNumber := r.config.startNumber
+	for i, table := range parsedTables {
 
-	// replace the old styles.xml with the newly generated content
-	odt.styles = newStylesXML
-	newStylesXML = ""
+		if table == nil && errs[i] == nil {
+			continue
+		}
 
-	//
-	// Append the string text
-	//
+		// an overridden number also becomes the start of those after it
+		if override, ok := r.numbers[r.tables[i]]; ok {
+			tableNumber = override
+		}
+		number := tableNumber
+		tableNumber++
+
+		// tables which failed to convert keep their number, but are
+		// replaced by a note of what went wrong
+		if errs[i] != nil {
+			placeholder := fmt.Sprintf("Table %d failed to convert: %s", number, errs[i])
+			switch OutputFormat {
+			case "csv":
+				csvWriter.AddPlaceholder(placeholder)
+			case "json":
+				jsonWriter.AddPlaceholder(placeholder)
+			default:
+				odtWriter.AddPlaceholder(placeholder)
+			}
+			continue
+		}
+		table.Number = number
 
-	pieces := strings.Split(odt.content, "<text:p text:style-name=\"Standard\"/>")
-	if len(pieces) != 2 {
-		return fmt.Errorf("AppendString() --> malformed template, consider replacing the ODT template")
+		switch OutputFormat {
+		case "csv":
+			csvWriter.AddTable(table)
+		case "json":
+			jsonWriter.AddTable(table)
+		default:
+			odtWriter.AddTable(table, odt.TableOptions{
+				Alignments: r.alignments[r.tables[i]],
+				Widths:     r.widths[r.tables[i]],
+				Landscape:  r.landscape[r.tables[i]],
+			})
+		}
 	}
 
-	lines := strings.Split(data, "\n")
-	if len(lines) == 0 {
-		return nil
+	// write the CSV, JSON or ODT file with the rosewood file contents
+	var writer io.WriterTo = odtWriter
+	switch OutputFormat {
+	case "csv":
+		writer = csvWriter
+	case "json":
+		writer = jsonWriter
 	}
 
-	newContentXML += pieces[0]
-	for _, line := range lines {
-
-		// adjust the line to handle certain special characters
-		fixedLine := line
-		fixedLine = strings.Replace(fixedLine, ">", "&gt;", -1)
-		fixedLine = strings.Replace(fixedLine, "<", "&lt;", -1)
-		newContentXML += fixedLine
+	// the output is written beside the file it replaces, which is left
+	// as it was if anything goes wrong
+	tempPath := outputPath + ".tmp"
+	outputFile, err := os.Create(tempPath)
+	if err != nil {
+		return fmt.Errorf("Unable to create output file: %s", outputPath)
 	}
-	newContentXML += "<text:p text:style-name=\"Standard\"/>" + pieces[1]
 
-	odt.content = newContentXML
+	_, err = writer.WriteTo(outputFile)
+	if closeErr := outputFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempPath, outputPath)
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return err
+	}
 
-	//
-	// Handle table title elements
-	//
+	// finish with a summary of the tables skipped by -keep-going
+	if failureCount > 0 {
+		return fmt.Errorf("Unable to convert %d of %d tables:%s", failureCount, len(r.tables), failures)
+	}
 
-	newContentXML = strings.Replace(odt.content, ":scaffolding-table-title-start:", "<text:p text:style-name=\"P1\">", -1)
-	newContentXML = strings.Replace(newContentXML, ":scaffolding-table-title-end:", "</text:p>", -1)
+	return nil
+}
 
-	odt.content = newContentXML
-	newContentXML = ""
+// watch ... rebuild the output whenever any of the tables change, once they have been left
+// unchanged for the debounce period; this never returns
+func (r *Report) watch(outputPath string) {
 
-	//
-	// Handle start-of-column spacing
-	//
+	fmt.Printf("Watching %d tables in %s for changes...\n", len(r.tables), r.config.inputDir)
 
-	newContentXML = strings.Replace(odt.content, ":scaffolding-odt-space:", "<text:s text:c=\"6\"/>", -1)
-	odt.content = newContentXML
-	newContentXML = ""
+	builtSignature := r.signature()
+	currentSignature := builtSignature
+	changedAt := time.Now()
 
-	//
-	// Handle ODT page breaks
-	//
+	for {
+		time.Sleep(WatchInterval)
 
-	newContentXML = strings.Replace(odt.content, ":scaffolding-page-break:",
-		"<text:p text:style-name=\"Standard\"/><text:p text:style-name=\"Standard\"/>", -1)
+		// restart the debounce period whenever the tables change
+		signature := r.signature()
+		if signature != currentSignature {
+			currentSignature = signature
+			changedAt = time.Now()
+			continue
+		}
 
-	odt.content = newContentXML
-	newContentXML = ""
+		if currentSignature == builtSignature || time.Since(changedAt) < WatchDebounce {
+			continue
+		}
+		builtSignature = currentSignature
 
-	//
-	// Convert scaffolding table elements to ODT elements
-	//
+		// only the changed tables are parsed again, as the others are
+		// still cached
+		if err := r.write(outputPath); err != nil {
+			fmt.Fprintf(os.Stderr, "\n%s\n", err)
+			continue
+		}
+		fmt.Printf("%s rebuilt %s\n", time.Now().Format("15:04:05"), outputPath)
+	}
+}
 
-	newContentXML = odt.content
-	re1 := regexp.MustCompile(":scaffolding-table-start-(\\d+):")
-	newContentXML = re1.ReplaceAllString(newContentXML, `<table:table table:name="Table$1" table:style-name="Table$1">`)
-	newContentXML = strings.Replace(newContentXML, ":scaffolding-table-end:", "</table:table>", -1)
+// signature ... summarise the modification times and sizes of the files of the tables
+func (r *Report) signature() string {
 
-	re2 := regexp.MustCompile(":scaffolding-column-table-(\\d+\\.[A-Z]+)-len-(\\d+):")
-	newContentXML = re2.ReplaceAllString(newContentXML, `<table:table-column table:style-name="Table$1" table:number-columns-repeated="$2" />`)
+	signature := ""
+	for _, path := range r.paths {
 
-	// logic here is so that cells after 1 will be centred, do this by using style P3 (defined further above at the start of this function)
-	re3 := regexp.MustCompile(":scaffolding-cell-centred-start-table-(\\d+\\.[A-Z]+\\d+)::scaffolding-cell-bold:")
-	newContentXML = re3.ReplaceAllString(newContentXML, `<table:table-cell table:style-name="Table$1" office:value-type="string"><text:p text:style-name="P5">`)
+		fileInfo, err := os.Stat(path)
+		if err != nil {
+			signature += path + ":missing\n"
+			continue
+		}
 
-	re4 := regexp.MustCompile(":scaffolding-cell-centred-start-table-(\\d+\\.[A-Z]+\\d+):")
-	newContentXML = re4.ReplaceAllString(newContentXML, `<table:table-cell table:style-name="Table$1" office:value-type="string"><text:p text:style-name="P3">`)
+		signature += path + ":" + fileInfo.ModTime().String() + ":" + strconv.FormatInt(fileInfo.Size(), 10) + "\n"
+	}
 
-	re5 := regexp.MustCompile(":scaffolding-cell-start-table-(\\d+\\.[A-Z]+\\d+)::scaffolding-cell-bold:")
-	newContentXML = re5.ReplaceAllString(newContentXML, `<table:table-cell table:style-name="Table$1" office:value-type="string"><text:p text:style-name="P4">`)
+	return signature
+}
 
-	re6 := regexp.MustCompile(":scaffolding-cell-start-table-(\\d+\\.[A-Z]+\\d+):")
-	newContentXML = re6.ReplaceAllString(newContentXML, `<table:table-cell table:style-name="Table$1" office:value-type="string"><text:p text:style-name="Standard">`)
+// readTables ... read and parse the given Rosewood files with up to the given number of
+// concurrent jobs, passing back the tables and errors in the same order as the paths
+func readTables(paths []string, jobs int, cache *tableCache) ([]*rosewood.Table, []error) {
 
-	// TODO: test the covered-table-cell replacer below to ensure that it actually works
-	newContentXML = strings.Replace(newContentXML, ":scaffolding-row-start:", "<table:table-row>", -1)
-	newContentXML = strings.Replace(newContentXML, ":scaffolding-cell-end:", "</text:p></table:table-cell>", -1)
-	newContentXML = strings.Replace(newContentXML, ":scaffolding-covered-cell:", "<table:covered-table-cell/>", -1)
-	newContentXML = strings.Replace(newContentXML, ":scaffolding-row-end:", "</table:table-row>", -1)
+	tables := make([]*rosewood.Table, len(paths))
+	errs := make([]error, len(paths))
 
-	// replace the old content.xml with the newly generated content
-	odt.content = newContentXML
-	newContentXML = ""
+	runJobs(len(paths), jobs, func(i int) {
+		tables[i], errs[i] = readTable(paths[i], cache)
+	})
 
-	return nil
+	return tables, errs
 }
 
-// Write ... take the modified ODT file in memory and write it to a file
-func (odt *Odt) Write(path string) error {
+// runJobs ... run the given job once per index from 0 to count - 1, with up to the given number of
+// jobs running at once, returning once they have all finished
+func runJobs(count int, jobs int, job func(i int)) {
 
-	if odt.files == nil || odt.content == "" || path == "" {
-		return fmt.Errorf("Write() --> invalid input")
+	if jobs < 1 {
+		jobs = 1
 	}
 
-	newFile, err := os.Create(path)
-	if err != nil {
-		return fmt.Errorf("Write() --> unable to create ODT file: " + path)
+	// each worker runs whichever job is next, which keeps its results
+	// at its own index so that the original order is kept
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for j := 0; j < jobs && j < count; j++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				job(i)
+			}
+		}()
 	}
-	defer newFile.Close()
-
-	zipWriter := zip.NewWriter(newFile)
-
-	for _, file := range odt.files {
-
-		var writer io.Writer
-		var readCloser io.ReadCloser
-
-		writer, err = zipWriter.Create(file.Name)
-		if err != nil {
-			return err
-		}
-
-		readCloser, err = file.Open()
-		if err != nil {
-			return err
-		}
 
-		//
-		// Handle each of the subfiles of interest
-		//
-
-		switch file.Name {
-
-		case "content.xml":
-			writer.Write([]byte(odt.content))
-		case "styles.xml":
-			writer.Write([]byte(odt.styles))
-		case "settings.xml":
-			writer.Write([]byte(odt.settings))
-		default:
-			writer.Write(streamToByte(readCloser))
-		}
+	for i := 0; i < count; i++ {
+		indexes <- i
 	}
-	zipWriter.Close()
-
-	return nil
+	close(indexes)
+	wg.Wait()
 }
 
-// streamToByte ... convert a string stream to a byte array
-func streamToByte(stream io.Reader) []byte {
+// parseTable ... parse a table given either as Rosewood text or as JSON
+func parseTable(byteContents []byte) (*rosewood.Table, error) {
 
-	if stream == nil {
-		return []byte{}
+	if rosewood.IsJSON(byteContents) {
+		return rosewood.ParseJSON(bytes.NewReader(byteContents))
 	}
 
-	buf := new(bytes.Buffer)
-	buf.ReadFrom(stream)
-
-	return buf.Bytes()
+	return rosewood.Parse(bytes.NewReader(byteContents))
 }
 
-// obtainStylesFromScaffolding ... turn scaffolding elements into ODT styles
-func obtainStylesFromScaffolding(data string) (string, error) {
+// readTable ... read and parse a Rosewood or JSON file, along with the details of its source, unless it
+// is unchanged since it was cached; empty files give a nil table
+func readTable(path string, cache *tableCache) (*rosewood.Table, error) {
 
-	if data == "" {
-		return "", fmt.Errorf("obtainStylesFromScaffolding(() --> invalid input")
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, err
 	}
 
-	regexStyles := regexp.MustCompile(SCAFFOLDING_TABLE_COLS + "(\\d+)-rows-(\\d+):")
-	matches := regexStyles.FindAllStringSubmatch(data, -1)
+	// the cached table is copied, as its number may differ this time
+	if cache != nil {
+		cache.Lock()
+		cached, ok := cache.entries[path]
+		cache.Unlock()
 
-	// ISO standard for the ODT suggests starting with the ASCII value of "A"
-	startingLetter := 65
+		if ok && cached.modified.Equal(fileInfo.ModTime()) && cached.size == fileInfo.Size() {
+			if cached.table == nil {
+				return nil, nil
+			}
+			table := *cached.table
+			return &table, nil
+		}
+	}
 
-	styles := ""
+	byteContents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
 
-	for i, matchArray := range matches {
+	var table *rosewood.Table
+	if len(byteContents) > 0 {
 
-		// skip if invalid match array
-		if len(matchArray) != 3 {
-			continue
+		table, err = parseTable(byteContents)
+		if err != nil {
+			return nil, err
 		}
 
-		tableNumStr := strconv.Itoa(i + 1)
-		columns, err := strconv.ParseInt(matchArray[1], 10, 64)
+		// gather the details of the source file, for the page headers,
+		// footers and provenance notes
+		absolutePath, err := filepath.Abs(path)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
+		table.Source = &rosewood.Source{
+			Path:     absolutePath,
+			Checksum: fmt.Sprintf("%x", sha256.Sum256(byteContents)),
+			Modified: fileInfo.ModTime(),
+		}
+	}
 
-		// TODO: re-enable this at some future date for handling single-cell row contexts
-		//rows, err := strconv.ParseInt(matchArray[2], 10, 64)
-		//if err != nil {
-		//	return "", err
-		//}
-
-		//
-		// handle column styles
-		//
-		for j := 0; j < int(columns); j++ {
-
-			letterStr := string(byte(startingLetter + j))
-
-			styles += "<style:style style:name=\"Table" + tableNumStr + "." + letterStr + "1\" style:family=\"table-cell\">" +
-				"<style:table-cell-properties fo:padding=\"0.049cm\" fo:border-left=\"0.05pt solid #000000\" " +
-				"fo:border-right=\"0.05pt solid #000000\" fo:border-top=\"0.05pt solid #000000\" fo:border-bottom=\"0.05pt solid #000000\"/>" +
-				"</style:style>"
+	if cache != nil {
+		cache.Lock()
+		cache.entries[path] = cachedTable{modified: fileInfo.ModTime(), size: fileInfo.Size(), table: table}
+		cache.Unlock()
+	}
 
-			styles += "<style:style style:name=\"Table" + tableNumStr + "." + letterStr + "2\" style:family=\"table-cell\">" +
-				"<style:table-cell-properties fo:padding=\"0.049cm\" fo:border-left=\"0.05pt solid #000000\" " +
-				"fo:border-right=\"0.05pt solid #000000\" fo:border-top=\"0.05pt solid #000000\" fo:border-bottom=\"0.05pt solid #000000\"/>" +
-				"</style:style>"
-		}
+	if table == nil {
+		return nil, nil
 	}
 
-	return styles, nil
+	copied := *table
+	return &copied, nil
 }
@@ -1,20 +0,0 @@
-package main
-
-const (
-	// scaffolding language tags
-	SCAFFOLDING_TABLE_TITLE_START        = ":scaffolding-table-title-start:"
-	SCAFFOLDING_TABLE_TITLE_END          = ":scaffolding-table-title-end:"
-	SCAFFOLDING_TABLE_START              = ":scaffolding-table-start-"
-	SCAFFOLDING_TABLE_COLS               = ":scaffolding-table-cols-"
-	SCAFFOLDING_TABLE_END                = ":scaffolding-table-end:"
-	SCAFFOLDING_COLUMN_TABLE             = ":scaffolding-column-table-"
-	SCAFFOLDING_ROW_START                = ":scaffolding-row-start:"
-	SCAFFOLDING_ROW_END                  = ":scaffolding-row-end:"
-	SCAFFOLDING_CELL_CENTRED_START_TABLE = ":scaffolding-cell-centred-start-table-"
-	SCAFFOLDING_CELL_START_TABLE         = ":scaffolding-cell-start-table-"
-	SCAFFOLDING_CELL_BOLD                = ":scaffolding-cell-bold:"
-	SCAFFOLDING_COVERED_CELL_END         = ":scaffolding-covered-cell-end:"
-	SCAFFOLDING_CELL_END                 = ":scaffolding-cell-end:"
-	SCAFFOLDING_ODT_SPACE                = ":scaffolding-odt-space:"
-	SCAFFOLDING_PAGE_BREAK               = ":scaffolding-page-break:"
-)
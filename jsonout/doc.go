@@ -0,0 +1,7 @@
+/*
+Package jsonout writes Rosewood tables as a JSON document, for use by other
+programs.
+
+	_, err := jsonout.NewWriter().AddTable(table).WriteTo(file)
+*/
+package jsonout
@@ -0,0 +1,123 @@
+package jsonout
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/rbisewski/scaffolding/rosewood"
+)
+
+// Writer ... assembles Rosewood tables into a JSON document
+type Writer struct {
+
+	// program named in the document as having converted the tables
+	Generator string
+
+	entries []entry
+	err     error
+}
+
+// entry ... a table added to the output, or a placeholder added in place of one
+type entry struct {
+	table       *rosewood.Table
+	placeholder string
+}
+
+// document ... the JSON document written, its tables being either tables or placeholders
+type document struct {
+	Generator string            `json:"generator,omitempty"`
+	Tables    []json.RawMessage `json:"tables"`
+}
+
+// placeholder ... the JSON form of a placeholder
+type placeholder struct {
+	Placeholder string `json:"placeholder"`
+}
+
+// NewWriter ... pass back a new JSON writer with the default settings
+func NewWriter() *Writer {
+	return &Writer{}
+}
+
+// AddTable ... append a table to the output; any error is passed back by WriteTo
+func (w *Writer) AddTable(t *rosewood.Table) *Writer {
+
+	if t == nil {
+		w.err = fmt.Errorf("AddTable() --> invalid input")
+		return w
+	}
+
+	w.entries = append(w.entries, entry{table: t})
+
+	return w
+}
+
+// AddPlaceholder ... append an object of the given text in place of a table, e.g. of one that
+// failed to convert
+func (w *Writer) AddPlaceholder(text string) *Writer {
+
+	w.entries = append(w.entries, entry{placeholder: text})
+
+	return w
+}
+
+// WriteTo ... write the tables as a JSON object of the generator and an array of the tables, each
+// of which is described by rosewood.Table.MarshalJSON
+func (w *Writer) WriteTo(out io.Writer) (int64, error) {
+
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	if out == nil {
+		return 0, fmt.Errorf("WriteTo() --> invalid input")
+	}
+
+	doc := document{
+		Generator: w.Generator,
+		Tables:    make([]json.RawMessage, 0, len(w.entries)),
+	}
+
+	for _, e := range w.entries {
+
+		var encoded []byte
+		var err error
+		if e.table != nil {
+			encoded, err = encode(e.table, "")
+		} else {
+			encoded, err = encode(placeholder{Placeholder: e.placeholder}, "")
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		doc.Tables = append(doc.Tables, encoded)
+	}
+
+	result, err := encode(doc, "  ")
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := out.Write(append(result, '\n'))
+
+	return int64(n), err
+}
+
+// encode ... encode a value as JSON, indented by the given string, if any; cells such as "<0.001"
+// are kept as they are, rather than escaped for embedding in HTML
+func encode(v interface{}, indent string) ([]byte, error) {
+
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", indent)
+
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimRight(encoded.Bytes(), "\n"), nil
+}
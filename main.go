@@ -22,8 +22,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"path/filepath"
+	"runtime"
 	"strings"
+	"time"
+
+	"github.com/rbisewski/scaffolding/odt"
 )
 
 //
@@ -36,20 +39,70 @@ var (
 	Version = "0.0"
 	Build   = "unknown"
 
+	// Commands of the program, keyed by name
+	Commands = map[string]func([]string) error{
+		"convert":   convert,
+		"validate":  validate,
+		"inspect":   inspect,
+		"diff":      diff,
+		"serve":     serve,
+		"templates": listTemplates,
+		"help":      help,
+	}
+
 	// Whether or not to print the version + build information
 	PrintVersionArgument = false
 
-	// Whether to print CSV or ODT output
+	// Whether to print CSV or ODT output; shorthand for an OutputFormat of csv
 	PrintAsCSV = false
 
+	// Format of the output: odt, csv or json
+	OutputFormat = "odt"
+
+	// Whether to begin the ODT output with a list of tables
+	PrintTableIndex = false
+
+	// Whether to begin the ODT output with a title page
+	PrintTitlePage = false
+
+	// Whether to follow each table with a note of its source file
+	PrintProvenance = false
+
+	// Page header and footer templates of "left|centre|right" sections
+	PageHeader = ""
+	PageFooter = "||{page}"
+
+	// Heading of the list of tables
+	TableIndexTitle = "List of Tables"
+
+	// Estimated number of list of tables entries that fit on a page
+	TableIndexEntriesPerPage = 40
+
 	// Default CSV output file name
 	DefaultCSVOutputFilename = "rosewood.csv"
 
 	// Default ODT output file name
 	DefaultODTOutputFilename = "rosewood.odt"
 
+	// Default JSON output file name
+	DefaultJSONOutputFilename = "rosewood.json"
+
 	// Default templates directory
 	DefaultTemplatesDir = "templates"
+
+	// Largest request body, in bytes, accepted by the conversion service
+	DefaultMaxRequestSize int64 = 10 << 20
+
+	// How often -watch checks the tables for changes, and how long they
+	// must then be left unchanged before the output is rebuilt
+	WatchInterval = 500 * time.Millisecond
+	WatchDebounce = 1 * time.Second
+
+	// Theme applied to the generated tables, which a -theme file may override
+	TableTheme = odt.DefaultTheme
+
+	// Widest width, in cm, an estimated ODT table column may have
+	MaxColumnWidth = 6.0
 )
 
 //
@@ -57,137 +110,93 @@ var (
 //
 func main() {
 
-	var config = Config{
-		tables:    "",
-		inputDir:  ".",
-		outputDir: ".",
+	// the first argument names the command, else the flat invocation of
+	// old is taken to be a conversion
+	command := "convert"
+	args := os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command = args[0]
+		args = args[1:]
 	}
 
-	err := setupArguments(&config)
-	if err != nil {
-		fatal(err)
+	run, ok := Commands[command]
+	if !ok {
+		fmt.Println(usageMessage)
+		fatal(fmt.Errorf("Unknown command: %s. Please use convert, validate, inspect, diff, serve or templates.", command))
 	}
 
-	// if the version flag has been set to true, print the version
-	// information and quit
-	if PrintVersionArgument {
-		fmt.Printf("Scaffolding ODT Generator v%s, Build: %s\n", Version, Build)
+	err := run(args)
+	if err == flag.ErrHelp {
 		os.Exit(0)
 	}
-
-	// by default, use the current directory if none is specified
-	if config.inputDir == "" {
-		config.inputDir = "."
-	}
-	if config.outputDir == "" {
-		config.outputDir = "."
-	}
-
-	// validate input
-	if err := validArgument(&config); err != nil {
-		fmt.Println(usageMessage)
+	if err != nil {
 		fatal(err)
 	}
+}
 
-	// split the table list into a string[]
-	tables := strings.Split(config.tables, ",")
-
-	tablePaths := make([]string, 0)
-	for _, t := range tables {
-		newPath := filepath.Join(config.inputDir, t)
-		tablePaths = append(tablePaths, newPath)
-	}
+// Setup the program arguments of the convert command
+func setupArguments(flags *flag.FlagSet, config *Config) error {
 
-	if len(tablePaths) == 0 {
-		fmt.Println("Error: unable to alloc mem for table filepaths.")
-		os.Exit(1)
+	// input validation
+	if flags == nil || config == nil {
+		return fmt.Errorf("setupArguments() --> invalid config")
 	}
 
-	// create output paths to for where the files will be generated
-	defaultOutputFilename := DefaultODTOutputFilename
-	if PrintAsCSV {
-		defaultOutputFilename = DefaultCSVOutputFilename
+	flags.Usage = func() {
+		fmt.Println(usageMessage)
 	}
-	outputFilepath := filepath.Join(config.outputDir, defaultOutputFilename)
 
-	// cycle thru every file
-	contents := ""
-	for i, path := range tablePaths {
+	flags.BoolVar(&PrintAsCSV, "csv", false, "")
+	flags.StringVar(&OutputFormat, "format", OutputFormat, "")
+	flags.BoolVar(&PrintTableIndex, "index", false, "")
+	flags.BoolVar(&PrintTitlePage, "title-page", false, "")
+	flags.BoolVar(&PrintProvenance, "provenance", false, "")
+	flags.StringVar(&config.tables, "tables", "", "")
+	flags.StringVar(&config.inputDir, "indir", ".", "")
+	flags.StringVar(&config.outputDir, "outdir", ".", "")
+	flags.IntVar(&config.jobs, "jobs", runtime.NumCPU(), "")
+	flags.BoolVar(&config.keepGoing, "keep-going", false, "")
+	flags.BoolVar(&config.watch, "watch", false, "")
+	flags.StringVar(&config.alignments, "align", "", "")
+	flags.StringVar(&config.widths, "widths", "", "")
+	flags.Float64Var(&MaxColumnWidth, "max-col-width", MaxColumnWidth, "")
+	flags.StringVar(&config.landscape, "landscape", "", "")
+	flags.StringVar(&config.preset, "preset", "", "")
+	flags.StringVar(&config.themeFile, "theme", "", "")
+	flags.StringVar(&config.caption, "caption", "", "")
+	flags.IntVar(&config.startNumber, "start-number", 1, "")
+	flags.StringVar(&config.numbers, "numbers", "", "")
+	flags.StringVar(&config.title, "title", "", "")
+	flags.StringVar(&config.author, "author", "", "")
+	flags.StringVar(&config.subject, "subject", "", "")
+	flags.StringVar(&config.keywords, "keywords", "", "")
+	flags.StringVar(&PageHeader, "header", PageHeader, "")
+	flags.StringVar(&PageFooter, "footer", PageFooter, "")
+	flags.BoolVar(&PrintVersionArgument, "version", false, "")
 
-		byteContents, err := ioutil.ReadFile(path)
-		if err != nil {
-			fatal(err)
-		}
-
-		rosewoodFileContents := string(byteContents)
-
-		if rosewoodFileContents == "" {
-			continue
-		}
-
-		rosewoodLines := strings.Split(rosewoodFileContents, "\n")
-
-		csvOutput, err := convertRosewoodToCSV(rosewoodLines, i+1)
-		if err != nil {
-			fatal(err)
-		}
-
-		// If CSV then just newlines, else if ODT then do page-breaks after each table
-		if PrintAsCSV {
-
-			// plain-text CSV
-			contents += csvOutput + "\n"
-
-		} else if contents == "" {
-
-			// first table of ODT
-			contents += csvOutput
+	return nil
+}
 
-		} else {
+// parseTableOptions ... split a list such as "table-a=lcd,table-b=lpp" into a map
+func parseTableOptions(list string) (map[string]string, error) {
 
-			// subsequent tables of ODT
-			contents += SCAFFOLDING_PAGE_BREAK + csvOutput
-		}
-	}
+	tableOptions := make(map[string]string)
 
-	// Print a plaint-text CSV file with the rosewood file contents
-	if PrintAsCSV {
-		err = ioutil.WriteFile(outputFilepath, []byte(contents), 0644)
-		if err != nil {
-			fatal(err)
-		}
-		os.Exit(0)
+	if list == "" {
+		return tableOptions, nil
 	}
 
-	// Print an ODT file with the rosewood file contents
-	odtTemplate, err := ReadOdtFile("odt_blank_template")
-	newOdtFile := odtTemplate.New()
-	newOdtFile.AppendStrings(contents)
-	newOdtFile.Write(outputFilepath)
-	os.Exit(0)
-}
-
-// Setup the program arguments
-func setupArguments(config *Config) error {
+	for _, entry := range strings.Split(list, ",") {
 
-	// input validation
-	if config == nil {
-		return fmt.Errorf("setupArguments() --> invalid config")
-	}
+		pieces := strings.Split(entry, "=")
+		if len(pieces) != 2 || pieces[0] == "" {
+			return nil, fmt.Errorf("Invalid table option: %s. Please use the form table=value.", entry)
+		}
 
-	flag.Usage = func() {
-		fmt.Println(usageMessage)
+		tableOptions[strings.TrimSpace(pieces[0])] = strings.TrimSpace(pieces[1])
 	}
 
-	flag.BoolVar(&PrintAsCSV, "csv", false, "")
-	flag.StringVar(&config.tables, "tables", "", "")
-	flag.StringVar(&config.inputDir, "indir", ".", "")
-	flag.StringVar(&config.outputDir, "outdir", ".", "")
-	flag.BoolVar(&PrintVersionArgument, "version", false, "")
-
-	flag.Parse()
-
-	return nil
+	return tableOptions, nil
 }
 
 //validArgument returns an error if a necessary argument is missing
@@ -197,13 +206,25 @@ func validArgument(config *Config) error {
 		return fmt.Errorf("Invalid table names. Please enter a valid list of tables.")
 	}
 
+	if OutputFormat != "odt" && OutputFormat != "csv" && OutputFormat != "json" {
+		return fmt.Errorf("Invalid format: %s. Please use odt, csv or json.", OutputFormat)
+	}
+
 	// validation to ensure that inputDir actually corresponds to a valid path
 	if config.inputDir == "" {
 		return fmt.Errorf("Invalid input directory. Please enter a valid input directory.")
 	}
 	_, err := ioutil.ReadDir(config.inputDir)
 	if err != nil {
-		fatal(fmt.Errorf("Warning: the following is an invalid directory path --> " + config.inputDir))
+		fatal(fmt.Errorf("Warning: the following is an invalid directory path --> %s", config.inputDir))
+	}
+
+	if config.jobs < 1 {
+		return fmt.Errorf("Invalid number of jobs. Please enter a whole number of at least 1.")
+	}
+
+	if MaxColumnWidth <= 0 {
+		return fmt.Errorf("Invalid maximum column width. Please enter a width in cm greater than 0.")
 	}
 
 	// validation to ensure that outputDir actually corresponds to a valid path
@@ -212,7 +233,7 @@ func validArgument(config *Config) error {
 	}
 	_, err = ioutil.ReadDir(config.outputDir)
 	if err != nil {
-		fatal(fmt.Errorf("Warning: the following is an invalid directory path --> " + config.outputDir))
+		fatal(fmt.Errorf("Warning: the following is an invalid directory path --> %s", config.outputDir))
 	}
 
 	return nil
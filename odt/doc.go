@@ -0,0 +1,11 @@
+/*
+Package odt writes Rosewood tables into ISO standard ODT documents.
+
+	template, err := odt.ReadTemplate("templates/odt_blank_template")
+	...
+	_, err = odt.NewWriter(template).AddTable(table).WriteTo(file)
+
+The Writer may be given a theme, caption template, page header and footer,
+document metadata, a title page and a list of tables before it is written.
+*/
+package odt
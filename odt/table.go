@@ -0,0 +1,648 @@
+package odt
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rbisewski/scaffolding/rosewood"
+)
+
+// column alignments
+const (
+	AlignAuto    = "auto"
+	AlignLeft    = "left"
+	AlignCentre  = "centre"
+	AlignRight   = "right"
+	AlignDecimal = "decimal"
+	AlignParen   = "paren"
+)
+
+// kinds of rows, for purposes of styling; the header, the body, the
+// alternate body, the last and the alternate last rows, then the first,
+// middle and last of several header rows
+const (
+	rowHeader        = 1
+	rowBody          = 2
+	rowAlternate     = 3
+	rowLast          = 4
+	rowLastAlternate = 5
+	rowHeaderFirst   = 6
+	rowHeaderMiddle  = 7
+	rowHeaderLast    = 8
+)
+
+// isHeaderRow ... whether the given kind of row is a header row
+func isHeaderRow(row int) bool {
+	return row == rowHeader || row >= rowHeaderFirst
+}
+
+// TableOptions ... per-table settings of the ODT output
+type TableOptions struct {
+
+	// one letter per column; a(uto), l(eft), c(entre), r(ight),
+	// d(ecimal) or p(arenthesis)
+	Alignments string
+
+	// colon separated column widths in cm, e.g. "4.5:a:2cm", where
+	// blank or "a" entries keep the estimated width
+	Widths string
+
+	// whether to always place the table on a landscape page
+	Landscape bool
+}
+
+// Check ... whether the alignments and widths of the options are well formed, before any table is
+// laid out with them; those given for more columns than a table has are still refused by WriteTo
+func (o TableOptions) Check() error {
+
+	if _, err := parseAlignments(o.Alignments); err != nil {
+		return err
+	}
+
+	_, err := parseWidths(o.Widths)
+
+	return err
+}
+
+// table ... a table added to the document, along with its layout; placeholders are added in
+// place of tables, and have no Table
+type table struct {
+	*rosewood.Table
+	placeholder string
+	options     TableOptions
+	widths      []float64
+	alignments  []string
+	landscape   bool
+}
+
+// layout ... determine the widths and alignments of the columns of the table, with the layout
+// settings of the given writer
+func (t *table) layout(w *Writer) error {
+
+	if t.Table == nil {
+		return nil
+	}
+
+	var err error
+
+	t.widths, err = w.columnWidths(t.Table, t.options.Widths)
+	if err != nil {
+		return err
+	}
+
+	t.alignments, err = columnAlignments(t.Table, t.options.Alignments)
+	if err != nil {
+		return err
+	}
+
+	// place the table on a landscape page if requested, or if it is too
+	// wide for the printable area of a portrait page
+	t.landscape = t.options.Landscape || t.width() > w.PrintableWidth
+
+	return nil
+}
+
+// width ... total width, in cm, of the columns of the table
+func (t *table) width() float64 {
+
+	width := 0.0
+	for _, w := range t.widths {
+		width += w
+	}
+
+	return width
+}
+
+// writeStyles ... write the ODT cell, column, table and tab stop styles of the nth table
+func (t *table) writeStyles(out *bufio.Writer, n int, theme *Theme) {
+
+	if t.Table == nil {
+		return
+	}
+
+	tableName := "Table" + strconv.Itoa(n)
+
+	//
+	// handle cell styles, of which rows 1 to 5 are the header, the body,
+	// the alternate body, the last and the alternate last rows, and 6 to
+	// 8 those of tables of several header rows
+	//
+	lastRow := rowLastAlternate
+	if len(t.Headers) > 1 {
+		lastRow = rowHeaderLast
+	}
+	for j := 0; j < t.Columns; j++ {
+		for row := rowHeader; row <= lastRow; row++ {
+			out.WriteString("<style:style style:name=\"" + tableName + "." + columnName(j) + strconv.Itoa(row) + "\" style:family=\"table-cell\">" +
+				theme.cellProperties(row) +
+				"</style:style>")
+		}
+	}
+
+	//
+	// handle table and column width styles
+	//
+	for j, width := range t.widths {
+		out.WriteString("<style:style style:name=\"" + tableName + "." + columnName(j) + "\" style:family=\"table-column\">" +
+			"<style:table-column-properties style:column-width=\"" + strconv.FormatFloat(width, 'f', 3, 64) + "cm\"/>" +
+			"</style:style>")
+	}
+
+	out.WriteString("<style:style style:name=\"" + tableName + "\" style:family=\"table\">" +
+		"<style:table-properties style:width=\"" + strconv.FormatFloat(t.width(), 'f', 3, 64) + "cm\" table:align=\"left\"/>" +
+		"</style:style>")
+
+	//
+	// handle tab aligned column styles, whose tab stop is placed at
+	// the middle of the column
+	//
+	for j, alignment := range t.alignments {
+
+		alignChar := ""
+		switch alignment {
+		case AlignDecimal:
+			alignChar = "."
+		case AlignParen:
+			alignChar = "("
+		default:
+			continue
+		}
+
+		positionStr := strconv.FormatFloat(t.widths[j]/2, 'f', 3, 64) + "cm"
+
+		out.WriteString("<style:style style:name=\"" + tableName + "." + columnName(j) + ".Tab\" style:family=\"paragraph\" style:parent-style-name=\"Table_20_Contents\">" +
+			"<style:paragraph-properties>" +
+			"<style:tab-stops>" +
+			"<style:tab-stop style:position=\"" + positionStr + "\" style:type=\"char\" style:char=\"" + alignChar + "\"/>" +
+			"</style:tab-stops>" +
+			"</style:paragraph-properties>" +
+			"</style:style>")
+	}
+}
+
+// writeContent ... write the ODT title paragraph, table and provenance note of the nth table
+func (t *table) writeContent(out *bufio.Writer, n int, w *Writer, titleStyle string) {
+
+	tableName := "Table" + strconv.Itoa(n)
+
+	// placeholders are printed in place of the title, without a table
+	if t.Table == nil {
+		out.WriteString("<text:p text:style-name=\"" + titleStyle + "\">" + html.EscapeString(t.placeholder) + "</text:p>")
+		return
+	}
+
+	// changed titles are marked with the ID of their tracked changes, if
+	// tracking them, else highlighted
+	titleChangeID := ""
+	if w.TrackChanges {
+		titleChangeID = trackedTitleID(n)
+	}
+
+	out.WriteString("<text:p text:style-name=\"" + titleStyle + "\">" + t.caption(n, w.caption(), w.Theme.Caption, titleChangeID))
+
+	// titles set the name of the source file of their table, for use
+	// by the page headers and footers
+	if w.usesSourceField() && t.Source != nil {
+		out.WriteString("<text:variable-set text:name=\"SourceFile\" office:value-type=\"string\" text:display=\"none\">" +
+			html.EscapeString(filepath.Base(t.Source.Path)) + "</text:variable-set>")
+	}
+	out.WriteString("</text:p>")
+
+	out.WriteString("<table:table table:name=\"" + tableName + "\" table:style-name=\"" + tableName + "\">")
+	for j := range t.widths {
+		out.WriteString("<table:table-column table:style-name=\"" + tableName + "." + columnName(j) + "\" />")
+	}
+
+	// changed cells are marked with the IDs of their tracked changes, if
+	// tracking them, else highlighted
+	changeID := func(r int) string {
+		if !w.TrackChanges {
+			return ""
+		}
+		return trackedChangeID(n, r)
+	}
+
+	// several header rows are styled as the first, middle or last
+	for i, cells := range t.Headers {
+		row := rowHeader
+		switch {
+		case len(t.Headers) == 1:
+		case i == 0:
+			row = rowHeaderFirst
+		case i == len(t.Headers)-1:
+			row = rowHeaderLast
+		default:
+			row = rowHeaderMiddle
+		}
+		t.writeRow(out, tableName, cells, row, changeID(i))
+	}
+
+	// body rows alternate between two styles, and the last one is
+	// styled separately
+	for i, cells := range t.Rows {
+		row := rowBody + i%2
+		if i == len(t.Rows)-1 {
+			row += 2
+		}
+		t.writeRow(out, tableName, cells, row, changeID(len(t.Headers)+i))
+	}
+
+	out.WriteString("</table:table>")
+
+	// follow the table with a note of where it came from, if requested
+	if w.Provenance && t.Source != nil {
+		out.WriteString("<text:p text:style-name=\"P13\">" + html.EscapeString(w.provenanceNote(t.Source)) + "</text:p>")
+	}
+}
+
+// caption ... generate the "Table N" label and title of the nth table in the given caption format;
+// a changed title is written as tracked changes of the given ID, if one is given
+func (t *table) caption(n int, template string, format string, changeID string) string {
+
+	numberStr := strconv.Itoa(t.Number)
+
+	// the label is everything up to and including the number, and the
+	// separator whatever lies between it and the title
+	label := template
+	separator := ""
+	rest := ""
+	if pieces := strings.SplitN(template, "{title}", 2); len(pieces) == 2 {
+		label = pieces[0]
+		rest = pieces[1]
+	}
+	if index := strings.LastIndex(label, "{n}"); index != -1 {
+		separator = label[index+len("{n}"):]
+		label = label[:index+len("{n}")]
+	}
+
+	// table numbers are sequence fields, so that they are recognised as captions
+	label = strings.Replace(html.EscapeString(label), "{n}",
+		"<text:sequence text:ref-name=\"refTable"+strconv.Itoa(n)+"\" text:name=\"Table\" text:formula=\"ooow:"+numberStr+
+			"\" style:num-format=\"1\">"+numberStr+"</text:sequence>", -1)
+	separator = html.EscapeString(separator)
+	rest = strings.Replace(rest, "{n}", numberStr, -1)
+
+	// a title changed since an earlier version of the table is marked
+	// as a changed cell would be
+	title := html.EscapeString(t.Title + rest)
+	if t.OldTitle != "" {
+		title = cellText(rosewood.Cell{Text: t.Title, OldText: t.OldTitle, Change: rosewood.ChangeChanged}, changeID, 0) +
+			html.EscapeString(rest)
+	}
+
+	switch format {
+	case CaptionLabelBold:
+		return "<text:span text:style-name=\"T1\">" + label + separator + "</text:span>" + title
+	case CaptionStacked:
+		return "<text:span text:style-name=\"T1\">" + label + "</text:span><text:line-break/>" +
+			"<text:span text:style-name=\"T2\">" + title + "</text:span>"
+	}
+
+	return label + separator + title
+}
+
+// writeRow ... write an ODT table row of the given kind; changed cells are written as tracked
+// changes of the given row ID, if one is given, marked by the index of the cell
+func (t *table) writeRow(out *bufio.Writer, tableName string, cells []rosewood.Cell, row int, changeID string) {
+
+	out.WriteString("<table:table-row>")
+
+	// the kth cell begins in the jth column, with those spanning several
+	// columns being followed by a covered cell per extra column
+	k := 0
+	for j := 0; j < t.Columns; j++ {
+
+		cell := rosewood.Cell{}
+		if k < len(cells) {
+			cell = cells[k]
+		}
+
+		span := cell.Spanned()
+		if span > t.Columns-j {
+			span = t.Columns - j
+		}
+
+		// determine how to align the cell contents; header and spanning
+		// cells of tab aligned columns are simply centred above them
+		alignment := AlignCentre
+		if j < len(t.alignments) {
+			alignment = t.alignments[j]
+		}
+		if (isHeaderRow(row) || span > 1) && (alignment == AlignDecimal || alignment == AlignParen) {
+			alignment = AlignCentre
+		}
+
+		paragraphStyle := "Table_20_Contents"
+		prefix := ""
+		switch alignment {
+		case AlignLeft:
+			if cell.Indent > 0 && cell.Bold {
+				paragraphStyle = "IndentBold" + strconv.Itoa(cell.Indent)
+			} else if cell.Indent > 0 {
+				paragraphStyle = "Indent" + strconv.Itoa(cell.Indent)
+			} else if cell.Bold {
+				paragraphStyle = "P4"
+			}
+		case AlignCentre:
+			paragraphStyle = "P3"
+			if cell.Bold {
+				paragraphStyle = "P5"
+			}
+		case AlignRight:
+			paragraphStyle = "P6"
+			if cell.Bold {
+				paragraphStyle = "P7"
+			}
+		case AlignDecimal, AlignParen:
+			paragraphStyle = tableName + "." + columnName(j) + ".Tab"
+			prefix = "<text:tab/>"
+		}
+
+		// numeric body cells carry their value, so that spreadsheet
+		// software is able to compute with them
+		valueAttributes := " office:value-type=\"string\""
+		if !isHeaderRow(row) && cell.Change != rosewood.ChangeRemoved {
+			if valueType, value, ok := cell.Value(); ok {
+				valueAttributes = " office:value-type=\"" + valueType + "\" office:value=\"" +
+					strconv.FormatFloat(value, 'g', -1, 64) + "\""
+			}
+		}
+
+		spanAttributes := ""
+		if span > 1 {
+			spanAttributes = " table:number-columns-spanned=\"" + strconv.Itoa(span) + "\""
+		}
+
+		out.WriteString("<table:table-cell table:style-name=\"" + tableName + "." + columnName(j) + strconv.Itoa(row) + "\"" + spanAttributes + valueAttributes + ">")
+		if cell.Text == "" && cell.OldText == "" {
+			out.WriteString("<text:p text:style-name=\"" + paragraphStyle + "\"/>")
+		} else {
+			out.WriteString("<text:p text:style-name=\"" + paragraphStyle + "\">" + prefix + cellText(cell, changeID, k) + "</text:p>")
+		}
+		out.WriteString("</table:table-cell>")
+
+		for c := 1; c < span; c++ {
+			out.WriteString("<table:covered-table-cell/>")
+		}
+		j += span - 1
+		k++
+	}
+
+	out.WriteString("</table:table-row>")
+}
+
+// cellText ... the escaped text of a cell; if it changed since an earlier version of its table,
+// the change is marked as tracked changes of the given row ID and cell index, else highlighted
+func cellText(cell rosewood.Cell, changeID string, index int) string {
+
+	deleted, inserted := cellChanges(cell)
+	if deleted == "" && inserted == "" {
+		return html.EscapeString(cell.Text)
+	}
+
+	// deletions are marked by where their text was, and insertions by
+	// where their text starts and ends
+	if changeID != "" {
+		id := changeID + "." + strconv.Itoa(index)
+		text := ""
+		if deleted != "" {
+			text += "<text:change text:change-id=\"" + id + "d\"/>"
+		}
+		if inserted != "" {
+			text += "<text:change-start text:change-id=\"" + id + "i\"/>" + html.EscapeString(inserted) +
+				"<text:change-end text:change-id=\"" + id + "i\"/>"
+		}
+		return text
+	}
+
+	// added text is highlighted green, and removed text struck out in
+	// red, being followed by any new text, highlighted yellow
+	if cell.Change == rosewood.ChangeAdded {
+		return "<text:span text:style-name=\"T3\">" + html.EscapeString(inserted) + "</text:span>"
+	}
+
+	text := ""
+	if deleted != "" {
+		text = "<text:span text:style-name=\"T4\">" + html.EscapeString(deleted) + "</text:span>"
+	}
+	if deleted != "" && inserted != "" {
+		text += " "
+	}
+	if inserted != "" {
+		text += "<text:span text:style-name=\"T5\">" + html.EscapeString(inserted) + "</text:span>"
+	}
+
+	return text
+}
+
+// cellChanges ... the text removed from and added to a cell since an earlier version of its table
+func cellChanges(cell rosewood.Cell) (string, string) {
+
+	switch cell.Change {
+	case rosewood.ChangeAdded:
+		return "", cell.Text
+	case rosewood.ChangeRemoved:
+		return cell.Text, ""
+	case rosewood.ChangeChanged:
+		return cell.OldText, cell.Text
+	}
+
+	return "", ""
+}
+
+// trackedChangeID ... the ID of the tracked changes of the rth row of the nth table, counting from
+// 0 across the header rows, if any, and then the body rows; the IDs of the changes themselves add the index of the cell and "d" or
+// "i", for deletions and insertions
+func trackedChangeID(n int, r int) string {
+	return "ct" + strconv.Itoa(n) + "." + strconv.Itoa(r)
+}
+
+// trackedTitleID ... the ID of the tracked changes of the title of the nth table; the IDs of the
+// changes themselves add ".0" and "d" or "i", as those of its cells do
+func trackedTitleID(n int) string {
+	return "ct" + strconv.Itoa(n) + ".t"
+}
+
+// columnWidths ... estimate the width, in cm, of each column of a table
+func (w *Writer) columnWidths(t *rosewood.Table, widthSpec string) ([]float64, error) {
+
+	rows := append(append([][]rosewood.Cell{}, t.Headers...), t.Rows...)
+
+	widths := make([]float64, t.Columns)
+	for i := range widths {
+		widths[i] = w.MinColumnWidth
+	}
+
+	// the padding drawn on either side of every cell
+	padding := w.Theme.cellPadding()
+
+	// size each column after its longest cell, including any indent;
+	// cells spanning several columns are left to fit within them
+	for _, cells := range rows {
+		i := 0
+		for _, cell := range cells {
+
+			width := float64(utf8.RuneCountInString(cell.Text))*w.CharacterWidth + 2*padding +
+				float64(cell.Indent)*w.IndentPerLevel
+
+			if cell.Spanned() == 1 && i < len(widths) && width > widths[i] {
+				widths[i] = width
+			}
+			i += cell.Spanned()
+		}
+	}
+
+	for i := range widths {
+		if widths[i] > w.MaxColumnWidth {
+			widths[i] = w.MaxColumnWidth
+		}
+	}
+
+	// apply the colon separated widths requested, if any; blank or "a"
+	// entries keep the estimated width
+	requested, err := parseWidths(widthSpec)
+	if err != nil {
+		return nil, err
+	}
+	for i, width := range requested {
+		if width > 0 && i < len(widths) {
+			widths[i] = width
+		}
+	}
+
+	return widths, nil
+}
+
+// parseWidths ... read colon separated column widths in cm, e.g. "4.5:a:2cm", giving 0 for blank
+// or "a" entries
+func parseWidths(widthSpec string) ([]float64, error) {
+
+	widths := make([]float64, 0)
+	if widthSpec == "" {
+		return widths, nil
+	}
+
+	for _, entry := range strings.Split(widthSpec, ":") {
+
+		entry = strings.TrimSpace(entry)
+		if entry == "" || entry == "a" {
+			widths = append(widths, 0)
+			continue
+		}
+
+		width, err := strconv.ParseFloat(strings.TrimSuffix(entry, "cm"), 64)
+		if err != nil || width <= 0 {
+			return nil, fmt.Errorf("parseWidths() --> invalid column width: %s", entry)
+		}
+		widths = append(widths, width)
+	}
+
+	return widths, nil
+}
+
+// parseAlignments ... read the alignment letters of each column, e.g. "lcd"
+func parseAlignments(alignSpec string) ([]string, error) {
+
+	alignments := make([]string, 0, len(alignSpec))
+
+	for _, letter := range alignSpec {
+		switch letter {
+		case 'a':
+			alignments = append(alignments, AlignAuto)
+		case 'l':
+			alignments = append(alignments, AlignLeft)
+		case 'c':
+			alignments = append(alignments, AlignCentre)
+		case 'r':
+			alignments = append(alignments, AlignRight)
+		case 'd':
+			alignments = append(alignments, AlignDecimal)
+		case 'p':
+			alignments = append(alignments, AlignParen)
+		default:
+			return nil, fmt.Errorf("parseAlignments() --> unknown alignment: %s", string(letter))
+		}
+	}
+
+	return alignments, nil
+}
+
+// columnAlignments ... determine the alignment of each column of a table
+func columnAlignments(t *rosewood.Table, alignSpec string) ([]string, error) {
+
+	// parse the per-column alignment letters requested, if any
+	alignments, err := parseAlignments(alignSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	// there is no column for letters past the last to align
+	if len(alignments) > t.Columns {
+		return nil, fmt.Errorf("columnAlignments() --> %d alignments given for a table of %d columns: %s",
+			len(alignments), t.Columns, alignSpec)
+	}
+
+	for len(alignments) < t.Columns {
+		alignments = append(alignments, AlignAuto)
+	}
+
+	// automatically aligned columns are placed left if first, on the
+	// opening parenthesis if they hold estimates with intervals, on the
+	// decimal point if purely numeric, else centred
+	for i, alignment := range alignments {
+
+		if alignment != AlignAuto {
+			continue
+		}
+
+		if i == 0 {
+			alignments[i] = AlignLeft
+			continue
+		}
+
+		numericCells := 0
+		intervalCells := 0
+		otherCells := 0
+		for _, cells := range t.Rows {
+
+			cell, ok := rosewood.CellAt(cells, i)
+			if !ok || cell.Text == "" || cell.Spanned() > 1 {
+				continue
+			}
+
+			if cell.IsNumeric() {
+				numericCells++
+			} else if cell.IsInterval() {
+				intervalCells++
+			} else {
+				otherCells++
+			}
+		}
+
+		if otherCells > 0 || numericCells+intervalCells == 0 {
+			alignments[i] = AlignCentre
+		} else if intervalCells > 0 {
+			alignments[i] = AlignParen
+		} else {
+			alignments[i] = AlignDecimal
+		}
+	}
+
+	return alignments, nil
+}
+
+// columnName ... spreadsheet style name of the jth column, i.e. A to Z, then AA, AB, etc
+func columnName(j int) string {
+
+	name := ""
+	for j++; j > 0; j = (j - 1) / 26 {
+		name = string(rune('A'+(j-1)%26)) + name
+	}
+
+	return name
+}
@@ -0,0 +1,158 @@
+package odt
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/rbisewski/scaffolding/rosewood"
+)
+
+func TestCellText(t *testing.T) {
+	tests := []struct {
+		name     string
+		cell     rosewood.Cell
+		changeID string
+		want     string
+	}{
+		{"unchanged", rosewood.Cell{Text: "a & b"}, "", "a &amp; b"},
+		{"added", rosewood.Cell{Text: "12", Change: rosewood.ChangeAdded}, "",
+			"<text:span text:style-name=\"T3\">12</text:span>"},
+		{"removed", rosewood.Cell{Text: "12", Change: rosewood.ChangeRemoved}, "",
+			"<text:span text:style-name=\"T4\">12</text:span>"},
+		{"changed", rosewood.Cell{Text: "13", OldText: "12", Change: rosewood.ChangeChanged}, "",
+			"<text:span text:style-name=\"T4\">12</text:span> <text:span text:style-name=\"T5\">13</text:span>"},
+		{"emptied", rosewood.Cell{OldText: "12", Change: rosewood.ChangeChanged}, "",
+			"<text:span text:style-name=\"T4\">12</text:span>"},
+		{"tracked unchanged", rosewood.Cell{Text: "12"}, "ct1.2", "12"},
+		{"tracked added", rosewood.Cell{Text: "12", Change: rosewood.ChangeAdded}, "ct1.2",
+			"<text:change-start text:change-id=\"ct1.2.1i\"/>12<text:change-end text:change-id=\"ct1.2.1i\"/>"},
+		{"tracked removed", rosewood.Cell{Text: "12", Change: rosewood.ChangeRemoved}, "ct1.2",
+			"<text:change text:change-id=\"ct1.2.1d\"/>"},
+		{"tracked changed", rosewood.Cell{Text: "13", OldText: "12", Change: rosewood.ChangeChanged}, "ct1.2",
+			"<text:change text:change-id=\"ct1.2.1d\"/>" +
+				"<text:change-start text:change-id=\"ct1.2.1i\"/>13<text:change-end text:change-id=\"ct1.2.1i\"/>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cellText(tt.cell, tt.changeID, 1); got != tt.want {
+				t.Errorf("cellText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaption(t *testing.T) {
+	tests := []struct {
+		name     string
+		table    rosewood.Table
+		format   string
+		changeID string
+		want     string
+	}{
+		{"inline", rosewood.Table{Title: "A & B", Number: 2}, CaptionInline, "", "Table <seq>2</seq>: A &amp; B"},
+		{"changed title", rosewood.Table{Title: "New", OldTitle: "Old", Number: 2}, CaptionInline, "",
+			"Table <seq>2</seq>: <text:span text:style-name=\"T4\">Old</text:span> <text:span text:style-name=\"T5\">New</text:span>"},
+		{"tracked title", rosewood.Table{Title: "New", OldTitle: "Old", Number: 2}, CaptionInline, "ct1.t",
+			"Table <seq>2</seq>: <text:change text:change-id=\"ct1.t.0d\"/>" +
+				"<text:change-start text:change-id=\"ct1.t.0i\"/>New<text:change-end text:change-id=\"ct1.t.0i\"/>"},
+		{"stacked", rosewood.Table{Title: "A", Number: 2}, CaptionStacked, "",
+			"<text:span text:style-name=\"T1\">Table <seq>2</seq></text:span><text:line-break/><text:span text:style-name=\"T2\">A</text:span>"},
+	}
+	sequence := regexp.MustCompile(`<text:sequence [^>]*>(\d+)</text:sequence>`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &table{Table: &tt.table}
+			got := sequence.ReplaceAllString(table.caption(1, CaptionTemplate(Theme{Caption: tt.format}), tt.format, tt.changeID), "<seq>$1</seq>")
+			if got != tt.want {
+				t.Errorf("caption() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnWidths(t *testing.T) {
+	table := &rosewood.Table{
+		Columns: 2,
+		Headers: [][]rosewood.Cell{{{Text: "Variable"}, {Text: "n"}}},
+		Rows:    [][]rosewood.Cell{{{Text: "Smoker", Indent: 2}, {Text: "10"}}},
+	}
+	tests := []struct {
+		name  string
+		theme Theme
+		spec  string
+		want  []float64
+	}{
+		{"default padding", DefaultTheme, "", []float64{6*0.21 + 2*0.5 + 2*0.049, 1.0}},
+		{"themed padding", Presets["nejm"], "", []float64{6*0.21 + 2*0.5 + 2*0.07, 1.0}},
+		{"padding in points", Theme{CellPadding: "2pt"}, "", []float64{6*0.21 + 2*0.5 + 4*2.54/72, 1.0}},
+		{"requested width", DefaultTheme, "a:2cm", []float64{6*0.21 + 2*0.5 + 2*0.049, 2.0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWriter(nil)
+			w.Theme = tt.theme
+			got, err := w.columnWidths(table, tt.spec)
+			if err != nil {
+				t.Fatalf("columnWidths() error = %v", err)
+			}
+			for i := range tt.want {
+				if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+					t.Errorf("columnWidths() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestColumnAlignments(t *testing.T) {
+	table, err := rosewood.Parse(strings.NewReader(oddsTable))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		spec    string
+		want    []string
+		wantErr bool
+	}{
+		{"automatic", "", []string{AlignLeft, AlignParen, AlignDecimal}, false},
+		{"partly given", "ld", []string{AlignLeft, AlignDecimal, AlignDecimal}, false},
+		{"every column", "lcr", []string{AlignLeft, AlignCentre, AlignRight}, false},
+		{"too many columns", "lccdd", nil, true},
+		{"unknown letter", "lx", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := columnAlignments(table, tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("columnAlignments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("columnAlignments() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableOptionsCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		options TableOptions
+		wantErr bool
+	}{
+		{"none", TableOptions{}, false},
+		{"given", TableOptions{Alignments: "lcdp", Widths: "4.5:a::2cm"}, false},
+		{"unknown alignment", TableOptions{Alignments: "lx"}, true},
+		{"invalid width", TableOptions{Widths: "abc"}, true},
+		{"zero width", TableOptions{Widths: "a:0"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.options.Check(); (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
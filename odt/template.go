@@ -0,0 +1,226 @@
+package odt
+
+import (
+	"archive/zip"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pageLayoutProperties ... the properties of the portrait page layout of a template
+var pageLayoutProperties = regexp.MustCompile(`<style:page-layout style:name="Mpm1">\s*<style:page-layout-properties ([^>]*)>`)
+
+// pageLayoutAttribute ... a length attribute of a page layout, e.g. fo:page-width="21.59cm"
+var pageLayoutAttribute = regexp.MustCompile(`fo:(page-width|page-height|margin-top|margin-bottom|margin-left|margin-right)="([^"]*)"`)
+
+// Template ... a blank ODT file whose content, metadata and styles are filled in by a Writer
+type Template struct {
+	files    []templateFile
+	content  string
+	meta     string
+	settings string
+	styles   string
+
+	// size and margins, in cm, of its portrait pages
+	page pageLayout
+}
+
+// templateFile ... a file of a template, read into memory so that the template need not be
+// kept open
+type templateFile struct {
+	name     string
+	contents []byte
+}
+
+// pageLayout ... the size and margins, in cm, of a page
+type pageLayout struct {
+	width        float64
+	height       float64
+	marginTop    float64
+	marginBottom float64
+	marginLeft   float64
+	marginRight  float64
+}
+
+// letterPage ... the layout of a US Letter page with 2cm margins, that of templates which do not
+// give their own
+var letterPage = pageLayout{width: 21.59, height: 27.94, marginTop: 2, marginBottom: 2, marginLeft: 2, marginRight: 2}
+
+// ReadTemplate ... read the contents of a blank ODT template file
+func ReadTemplate(path string) (*Template, error) {
+
+	if path == "" {
+		return nil, fmt.Errorf("ReadTemplate() --> invalid input")
+	}
+
+	//
+	// decompress the ODT file as it is in Zip format
+	//
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	//
+	// read every file into memory, as they are copied into each document written
+	//
+
+	files := make([]templateFile, 0, len(reader.File))
+	for _, f := range reader.File {
+
+		contents, err := readZipFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, templateFile{name: f.Name, contents: contents})
+	}
+
+	//
+	// obtain the strings from content.xml, settings.xml, and styles.xml
+	//
+
+	content, err := readFile(reader.File, "content.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := readFile(reader.File, "meta.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := readFile(reader.File, "settings.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	styles, err := readFile(reader.File, "styles.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Template{
+		files:    files,
+		content:  content,
+		meta:     meta,
+		settings: settings,
+		styles:   styles,
+		page:     readPageLayout(styles),
+	}, nil
+}
+
+// readPageLayout ... read the size and margins of the portrait pages of a template from its
+// styles, any not given being those of a Letter page
+func readPageLayout(styles string) pageLayout {
+
+	page := letterPage
+
+	properties := pageLayoutProperties.FindStringSubmatch(styles)
+	if properties == nil {
+		return page
+	}
+
+	for _, attribute := range pageLayoutAttribute.FindAllStringSubmatch(properties[1], -1) {
+
+		value, ok := length(attribute[2])
+		if !ok {
+			continue
+		}
+
+		switch attribute[1] {
+		case "page-width":
+			page.width = value
+		case "page-height":
+			page.height = value
+		case "margin-top":
+			page.marginTop = value
+		case "margin-bottom":
+			page.marginBottom = value
+		case "margin-left":
+			page.marginLeft = value
+		case "margin-right":
+			page.marginRight = value
+		}
+	}
+
+	return page
+}
+
+// printableWidth ... width, in cm, of the printable area of the page
+func (p pageLayout) printableWidth() float64 {
+	return p.width - p.marginLeft - p.marginRight
+}
+
+// landscape ... the same page, turned onto its side
+func (p pageLayout) landscape() pageLayout {
+	return pageLayout{
+		width:        p.height,
+		height:       p.width,
+		marginTop:    p.marginTop,
+		marginBottom: p.marginBottom,
+		marginLeft:   p.marginLeft,
+		marginRight:  p.marginRight,
+	}
+}
+
+// length ... convert an ODF length, e.g. "0.049cm", "2mm", "1in" or "1pt", into cm
+func length(value string) (float64, bool) {
+
+	value = strings.TrimSpace(value)
+
+	for unit, cm := range map[string]float64{"cm": 1, "mm": 0.1, "in": 2.54, "pt": 2.54 / 72} {
+		if !strings.HasSuffix(value, unit) {
+			continue
+		}
+		number, err := strconv.ParseFloat(strings.TrimSuffix(value, unit), 64)
+		if err != nil || number < 0 {
+			return 0, false
+		}
+		return number * cm, true
+	}
+
+	return 0, false
+}
+
+// readFile ... open the given file of the ODT template
+func readFile(files []*zip.File, filename string) (string, error) {
+
+	if len(files) == 0 || filename == "" {
+		return "", fmt.Errorf("readFile() --> invalid input")
+	}
+
+	var fileOfInterest *zip.File
+	for _, f := range files {
+		if f.Name == filename {
+			fileOfInterest = f
+			break
+		}
+	}
+
+	if fileOfInterest == nil {
+		return "", fmt.Errorf("readFile() --> %s not found", filename)
+	}
+
+	bytes, err := readZipFile(fileOfInterest)
+	if err != nil {
+		return "", err
+	}
+
+	return string(bytes), nil
+}
+
+// readZipFile ... read the whole of a file of a Zip archive
+func readZipFile(file *zip.File) ([]byte, error) {
+
+	readCloser, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer readCloser.Close()
+
+	return ioutil.ReadAll(readCloser)
+}
@@ -0,0 +1,80 @@
+package odt
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadPageLayout(t *testing.T) {
+	tests := []struct {
+		name      string
+		styles    string
+		want      pageLayout
+		wantWidth float64
+	}{
+		{"a4", `<style:page-layout style:name="Mpm1"><style:page-layout-properties fo:page-width="210mm" ` +
+			`fo:page-height="297mm" fo:margin-top="2cm" fo:margin-bottom="2cm" fo:margin-left="2.5cm" fo:margin-right="2.5cm"/>`,
+			pageLayout{width: 21, height: 29.7, marginTop: 2, marginBottom: 2, marginLeft: 2.5, marginRight: 2.5}, 16},
+		{"margins only", `<style:page-layout style:name="Mpm1"><style:page-layout-properties fo:margin-left="1in">`,
+			pageLayout{width: 21.59, height: 27.94, marginTop: 2, marginBottom: 2, marginLeft: 2.54, marginRight: 2}, 17.05},
+		{"no page layout", `<office:styles/>`, letterPage, 17.59},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readPageLayout(tt.styles)
+			if math.Abs(got.width-tt.want.width) > 1e-9 || math.Abs(got.height-tt.want.height) > 1e-9 ||
+				math.Abs(got.marginLeft-tt.want.marginLeft) > 1e-9 || got.marginTop != tt.want.marginTop {
+				t.Errorf("readPageLayout() = %+v, want %+v", got, tt.want)
+			}
+			if width := got.printableWidth(); math.Abs(width-tt.wantWidth) > 1e-9 {
+				t.Errorf("printableWidth() = %v, want %v", width, tt.wantWidth)
+			}
+		})
+	}
+}
+
+func TestReadTemplate(t *testing.T) {
+	contents, err := ioutil.ReadFile("../templates/odt_blank_template")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "template")
+	if err != nil {
+		t.Fatalf("TempDir() error = %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "odt_blank_template")
+	if err := ioutil.WriteFile(path, contents, 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	template, err := ReadTemplate(path)
+	if err != nil {
+		t.Fatalf("ReadTemplate() error = %v", err)
+	}
+
+	// the template is closed once read, so documents are still written after it is removed
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	var output bytes.Buffer
+	if _, err := NewWriter(template).WriteTo(&output); err != nil {
+		t.Errorf("WriteTo() error = %v", err)
+	}
+
+	if _, err := ReadTemplate(path); err == nil {
+		t.Errorf("ReadTemplate() of a missing file, want error")
+	}
+	if err := ioutil.WriteFile(path, []byte("not a zip file"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := ReadTemplate(path); err == nil {
+		t.Errorf("ReadTemplate() of a file not in Zip format, want error")
+	}
+}
@@ -0,0 +1,220 @@
+package odt
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+)
+
+// table border styles
+const (
+	BordersGrid       = "grid"
+	BordersThreeLine  = "three-line"
+	BordersHorizontal = "horizontal"
+	BordersNone       = "none"
+)
+
+// caption formats; "Table 1: Title", "**Table 1.** Title" or "**Table 1**"
+// with "*Title*" on the following line
+const (
+	CaptionInline    = "inline"
+	CaptionLabelBold = "label-bold"
+	CaptionStacked   = "stacked"
+)
+
+// Theme ... colours, fonts and borders applied to the generated tables
+type Theme struct {
+
+	// font, colour and size of the table titles
+	TitleFont   string `json:"title_font"`
+	TitleColour string `json:"title_colour"`
+	TitleSize   string `json:"title_size"`
+
+	// font and size of the table cells
+	BodyFont string `json:"body_font"`
+	BodySize string `json:"body_size"`
+
+	// one of "grid", "three-line", "horizontal" or "none"
+	Borders string `json:"borders"`
+
+	// border line, e.g. "0.05pt solid #000000"
+	BorderLine string `json:"border_line"`
+
+	// padding of every table cell, e.g. "0.049cm"
+	CellPadding string `json:"cell_padding"`
+
+	// background colours of the header row and of every other body row
+	HeaderShading string `json:"header_shading"`
+	ZebraStriping string `json:"zebra_striping"`
+
+	// one of "inline", "label-bold" or "stacked"
+	Caption string `json:"caption"`
+}
+
+// DefaultTheme ... theme used unless a preset or theme file says otherwise
+var DefaultTheme = Theme{
+	TitleColour: "#566cc9",
+	TitleSize:   "11pt",
+	Borders:     BordersGrid,
+	BorderLine:  "0.05pt solid #000000",
+	CellPadding: "0.049cm",
+	Caption:     CaptionInline,
+}
+
+// Presets ... journal style themes, which a theme file may further override
+var Presets = map[string]Theme{
+	"apa": {
+		TitleColour: "#000000",
+		TitleSize:   "12pt",
+		Borders:     BordersThreeLine,
+		BorderLine:  "0.5pt solid #000000",
+		CellPadding: "0.049cm",
+		Caption:     CaptionStacked,
+	},
+	"ama": {
+		TitleColour: "#000000",
+		TitleSize:   "11pt",
+		BodySize:    "10pt",
+		Borders:     BordersThreeLine,
+		BorderLine:  "0.5pt solid #000000",
+		CellPadding: "0.049cm",
+		Caption:     CaptionLabelBold,
+	},
+	"nejm": {
+		TitleFont:     "Liberation Sans",
+		TitleColour:   "#000000",
+		TitleSize:     "11pt",
+		BodyFont:      "Liberation Sans",
+		BodySize:      "9pt",
+		Borders:       BordersHorizontal,
+		BorderLine:    "0.05pt solid #c8b88a",
+		CellPadding:   "0.07cm",
+		ZebraStriping: "#fdf6e3",
+		Caption:       CaptionLabelBold,
+	},
+}
+
+// ReadTheme ... read a JSON theme file, its settings overriding those of the given theme
+func ReadTheme(path string, base Theme) (Theme, error) {
+
+	if path == "" {
+		return base, fmt.Errorf("ReadTheme() --> invalid input")
+	}
+
+	byteContents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return base, err
+	}
+
+	theme := base
+	err = json.Unmarshal(byteContents, &theme)
+	if err != nil {
+		return base, fmt.Errorf("ReadTheme() --> unable to parse theme file %s: %s", path, err)
+	}
+
+	switch theme.Borders {
+	case BordersGrid, BordersThreeLine, BordersHorizontal, BordersNone:
+	default:
+		return base, fmt.Errorf("ReadTheme() --> unknown border style: %s", theme.Borders)
+	}
+
+	switch theme.Caption {
+	case CaptionInline, CaptionLabelBold, CaptionStacked:
+	default:
+		return base, fmt.Errorf("ReadTheme() --> unknown caption format: %s", theme.Caption)
+	}
+
+	return theme, nil
+}
+
+// CaptionTemplate ... the caption template suited to the caption format of the given theme
+func CaptionTemplate(theme Theme) string {
+
+	switch theme.Caption {
+	case CaptionLabelBold:
+		return "Table {n}. {title}"
+	case CaptionStacked:
+		return "Table {n} {title}"
+	}
+
+	return "Table {n}: {title}"
+}
+
+// cellPadding ... the padding, in cm, of every table cell, or 0 if not given as a length
+func (theme *Theme) cellPadding() float64 {
+
+	if padding, ok := length(theme.CellPadding); ok {
+		return padding
+	}
+
+	return 0
+}
+
+// titleTextProperties ... ODT text properties of the table titles
+func (theme *Theme) titleTextProperties() string {
+
+	properties := ""
+	if theme.TitleFont != "" {
+		properties += " fo:font-family=\"&apos;" + html.EscapeString(theme.TitleFont) + "&apos;\""
+	}
+	if theme.TitleColour != "" {
+		properties += " fo:color=\"" + html.EscapeString(theme.TitleColour) + "\""
+	}
+	if theme.TitleSize != "" {
+		properties += " fo:font-size=\"" + html.EscapeString(theme.TitleSize) + "\""
+	}
+
+	return "<style:text-properties" + properties + " />"
+}
+
+// bodyTextProperties ... ODT text properties of the table cells
+func (theme *Theme) bodyTextProperties() string {
+
+	properties := ""
+	if theme.BodyFont != "" {
+		properties += " fo:font-family=\"&apos;" + html.EscapeString(theme.BodyFont) + "&apos;\""
+	}
+	if theme.BodySize != "" {
+		properties += " fo:font-size=\"" + html.EscapeString(theme.BodySize) + "\""
+	}
+
+	return "<style:text-properties" + properties + " />"
+}
+
+// cellProperties ... ODT table cell properties of the given kind of row
+func (theme *Theme) cellProperties(row int) string {
+
+	border := html.EscapeString(theme.BorderLine)
+	properties := " fo:padding=\"" + html.EscapeString(theme.CellPadding) + "\""
+
+	switch theme.Borders {
+
+	case BordersGrid:
+		properties += " fo:border-left=\"" + border + "\" fo:border-right=\"" + border + "\"" +
+			" fo:border-top=\"" + border + "\" fo:border-bottom=\"" + border + "\""
+
+	// rules between every row, without vertical lines
+	case BordersHorizontal:
+		properties += " fo:border-top=\"" + border + "\" fo:border-bottom=\"" + border + "\""
+
+	// a rule above and below the header rows, and below the last row
+	case BordersThreeLine:
+		switch row {
+		case rowHeader:
+			properties += " fo:border-top=\"" + border + "\" fo:border-bottom=\"" + border + "\""
+		case rowHeaderFirst:
+			properties += " fo:border-top=\"" + border + "\""
+		case rowHeaderLast, rowLast, rowLastAlternate:
+			properties += " fo:border-bottom=\"" + border + "\""
+		}
+	}
+
+	if isHeaderRow(row) && theme.HeaderShading != "" {
+		properties += " fo:background-color=\"" + html.EscapeString(theme.HeaderShading) + "\""
+	} else if (row == rowAlternate || row == rowLastAlternate) && theme.ZebraStriping != "" {
+		properties += " fo:background-color=\"" + html.EscapeString(theme.ZebraStriping) + "\""
+	}
+
+	return "<style:table-cell-properties" + properties + "/>"
+}
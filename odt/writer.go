@@ -0,0 +1,778 @@
+package odt
+
+import (
+	"archive/zip"
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rbisewski/scaffolding/rosewood"
+)
+
+// Metadata ... document properties written to the meta.xml of the ODT file
+type Metadata struct {
+	Title     string
+	Author    string
+	Subject   string
+	Keywords  []string
+	Created   time.Time
+	Generator string
+}
+
+// Writer ... assembles Rosewood tables into an ODT document
+type Writer struct {
+
+	// colours, fonts and borders of the tables
+	Theme Theme
+
+	// caption template of every table, e.g. "Table {n}: {title}"; if
+	// blank, the one suited to the caption format of the theme is used
+	Caption string
+
+	// page header and footer templates of "left|centre|right" sections,
+	// which may contain {page}, {pages}, {date}, {report} and {source}
+	Header string
+	Footer string
+
+	// document properties, also used by the title page
+	Metadata Metadata
+
+	// whether to begin the document with a title page
+	TitlePage bool
+
+	// whether to begin the document with a list of tables, its heading,
+	// and the estimated number of its entries that fit on a page
+	Index               bool
+	IndexTitle          string
+	IndexEntriesPerPage int
+
+	// whether to follow each table with a note of its source file
+	Provenance bool
+
+	// paragraph indent, in cm, given to each level of row indentation
+	IndentPerLevel float64
+
+	// width, in cm, of the printable area of a portrait page, beyond
+	// which tables are placed on landscape pages; by default that of
+	// the pages of the template
+	PrintableWidth float64
+
+	// estimated width, in cm, of a single character of table text, and
+	// the narrowest and widest widths an estimated column may have
+	CharacterWidth float64
+	MinColumnWidth float64
+	MaxColumnWidth float64
+
+	// whether cells marked as changed since an earlier version of their
+	// table are written as tracked changes, which reviewers may accept or
+	// reject, rather than highlighted
+	TrackChanges bool
+
+	template *Template
+	tables   []*table
+	err      error
+}
+
+// NewWriter ... pass back a new ODT writer with the default settings, based on the given template
+func NewWriter(template *Template) *Writer {
+
+	page := letterPage
+	if template != nil {
+		page = template.page
+	}
+
+	return &Writer{
+		Theme:               DefaultTheme,
+		Footer:              "||{page}",
+		IndexTitle:          "List of Tables",
+		IndexEntriesPerPage: 40,
+		IndentPerLevel:      0.5,
+		PrintableWidth:      page.printableWidth(),
+		CharacterWidth:      0.21,
+		MinColumnWidth:      1.0,
+		MaxColumnWidth:      6.0,
+		template:            template,
+	}
+}
+
+// AddTable ... append a table to the document, with options optionally given; any error is
+// passed back by WriteTo
+func (w *Writer) AddTable(t *rosewood.Table, options ...TableOptions) *Writer {
+
+	if t == nil {
+		w.err = fmt.Errorf("AddTable() --> invalid input")
+		return w
+	}
+
+	newTable := &table{Table: t}
+	if len(options) > 0 {
+		newTable.options = options[0]
+	}
+
+	w.tables = append(w.tables, newTable)
+
+	return w
+}
+
+// AddPlaceholder ... append a paragraph of text in place of a table, e.g. of one that failed to
+// convert
+func (w *Writer) AddPlaceholder(text string) *Writer {
+
+	w.tables = append(w.tables, &table{placeholder: text})
+
+	return w
+}
+
+// WriteTo ... write the ODT document to the given writer
+func (w *Writer) WriteTo(out io.Writer) (int64, error) {
+
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	if w.template == nil || out == nil {
+		return 0, fmt.Errorf("WriteTo() --> invalid input")
+	}
+
+	if w.MaxColumnWidth <= 0 || w.MinColumnWidth < 0 {
+		return 0, fmt.Errorf("WriteTo() --> invalid column width limits")
+	}
+
+	hasLandscapeTables := false
+	for _, t := range w.tables {
+		if t.Table != nil && t.Columns < 1 {
+			return 0, fmt.Errorf("WriteTo() --> table has no columns: %s", t.Title)
+		}
+		if err := t.layout(w); err != nil {
+			return 0, err
+		}
+		if t.landscape {
+			hasLandscapeTables = true
+		}
+	}
+
+	meta, err := w.meta()
+	if err != nil {
+		return 0, err
+	}
+
+	styles := w.styles(hasLandscapeTables)
+
+	counter := &countingWriter{writer: out}
+	zipWriter := zip.NewWriter(counter)
+
+	for _, file := range w.template.files {
+
+		writer, err := zipWriter.Create(file.name)
+		if err != nil {
+			return counter.count, err
+		}
+
+		//
+		// Handle each of the subfiles of interest
+		//
+
+		switch file.name {
+
+		// the tables are streamed into content.xml as they are converted
+		case "content.xml":
+			err = w.writeContent(writer, hasLandscapeTables)
+		case "meta.xml":
+			_, err = io.WriteString(writer, meta)
+		case "styles.xml":
+			_, err = io.WriteString(writer, styles)
+		case "settings.xml":
+			_, err = io.WriteString(writer, w.template.settings)
+		default:
+			_, err = writer.Write(file.contents)
+		}
+
+		if err != nil {
+			return counter.count, err
+		}
+	}
+
+	err = zipWriter.Close()
+
+	return counter.count, err
+}
+
+// caption ... caption template of every table
+func (w *Writer) caption() string {
+
+	if w.Caption != "" {
+		return w.Caption
+	}
+
+	return CaptionTemplate(w.Theme)
+}
+
+// usesSourceField ... whether the page header or footer displays the source file of the tables
+func (w *Writer) usesSourceField() bool {
+	return strings.Contains(w.Header+w.Footer, "{source}")
+}
+
+// provenanceNote ... describe where a table came from, and what converted it
+func (w *Writer) provenanceNote(source *rosewood.Source) string {
+
+	if w.Metadata.Generator == "" {
+		return source.String()
+	}
+
+	return source.String() + "; converted by " + w.Metadata.Generator
+}
+
+// writeContent ... write the content.xml of the document
+func (w *Writer) writeContent(writer io.Writer, hasLandscapeTables bool) error {
+
+	pieces := strings.Split(w.template.content, "<text:p text:style-name=\"Standard\"/>")
+	if len(pieces) != 2 {
+		return fmt.Errorf("writeContent() --> malformed template, consider replacing the ODT template")
+	}
+
+	// the automatic styles precede the body of the template
+	head := strings.SplitN(pieces[0], "<office:automatic-styles/>", 2)
+	if len(head) != 2 {
+		return fmt.Errorf("writeContent() --> malformed template, consider replacing the ODT template")
+	}
+
+	// titles set the name of the source file of their table, for use
+	// by the page headers and footers
+	if w.usesSourceField() {
+		head[1] = strings.Replace(head[1], "<text:sequence-decls>",
+			"<text:variable-decls>"+
+				"<text:variable-decl office:value-type=\"string\" text:name=\"SourceFile\"/>"+
+				"</text:variable-decls>"+
+				"<text:sequence-decls>", 1)
+	}
+
+	// tracked changes come first in the body of the document
+	if w.TrackChanges {
+		if !strings.Contains(head[1], "<office:text>") {
+			return fmt.Errorf("writeContent() --> malformed template, consider replacing the ODT template")
+		}
+		head[1] = strings.Replace(head[1], "<office:text>", "<office:text>"+w.trackedChanges(), 1)
+	}
+
+	out := bufio.NewWriter(writer)
+
+	//
+	// Append the new document styles
+	//
+
+	out.WriteString(head[0] + "<office:automatic-styles>" + w.automaticStyles(hasLandscapeTables))
+
+	maxIndent := 0
+	for i, t := range w.tables {
+		t.writeStyles(out, i+1, &w.Theme)
+		if t.Table == nil {
+			continue
+		}
+		for _, cells := range append(append([][]rosewood.Cell{}, t.Headers...), t.Rows...) {
+			for _, cell := range cells {
+				if cell.Indent > maxIndent {
+					maxIndent = cell.Indent
+				}
+			}
+		}
+	}
+
+	out.WriteString(indentStyles(maxIndent, w.IndentPerLevel) + "</office:automatic-styles>" + head[1])
+
+	//
+	// Append the title page, list of tables and the tables themselves
+	//
+
+	pagesBeforeIndex := 0
+	if w.TitlePage && len(w.tables) > 0 {
+		out.WriteString(w.titlePage())
+		pagesBeforeIndex = 1
+	}
+
+	if w.Index && len(w.tables) > 0 {
+		out.WriteString(w.tableIndex(pagesBeforeIndex))
+	}
+
+	// tables on landscape pages use the P8 title style, in which case
+	// the other titles use P9 to switch back onto portrait pages
+	portraitTitleStyle := "P1"
+	if hasLandscapeTables {
+		portraitTitleStyle = "P9"
+	}
+
+	for i, t := range w.tables {
+
+		if i > 0 {
+			out.WriteString("<text:p text:style-name=\"Standard\"/><text:p text:style-name=\"Standard\"/>")
+		}
+
+		titleStyle := portraitTitleStyle
+		if t.landscape {
+			titleStyle = "P8"
+		}
+
+		t.writeContent(out, i+1, w, titleStyle)
+	}
+
+	out.WriteString("<text:p text:style-name=\"Standard\"/>" + pieces[1])
+
+	// any error writing is kept by the buffer until it is flushed
+	return out.Flush()
+}
+
+// trackedChanges ... the changed regions of every changed title and every cell marked as changed,
+// being a deletion of its old text and an insertion of its new text
+func (w *Writer) trackedChanges() string {
+
+	author := w.Metadata.Author
+	if author == "" {
+		author = "scaffolding"
+	}
+	changeInfo := "<office:change-info>" +
+		"<dc:creator>" + html.EscapeString(author) + "</dc:creator>" +
+		"<dc:date>" + w.created().Format("2006-01-02T15:04:05") + "</dc:date>" +
+		"</office:change-info>"
+
+	regions := ""
+	addRegions := func(id string, deleted string, inserted string) {
+		if deleted != "" {
+			regions += "<text:changed-region text:id=\"" + id + "d\">" +
+				"<text:deletion>" + changeInfo + "<text:p>" + html.EscapeString(deleted) + "</text:p></text:deletion>" +
+				"</text:changed-region>"
+		}
+		if inserted != "" {
+			regions += "<text:changed-region text:id=\"" + id + "i\">" +
+				"<text:insertion>" + changeInfo + "</text:insertion>" +
+				"</text:changed-region>"
+		}
+	}
+
+	for n, t := range w.tables {
+
+		if t.Table == nil {
+			continue
+		}
+
+		// a changed title is a deletion and insertion within the caption
+		if t.OldTitle != "" {
+			addRegions(trackedTitleID(n+1)+".0", t.OldTitle, t.Title)
+		}
+
+		// the header rows, if any, are numbered first, and the body rows
+		// follow them
+		rows := append(append([][]rosewood.Cell{}, t.Headers...), t.Rows...)
+		for r, cells := range rows {
+			for j, cell := range cells {
+				deleted, inserted := cellChanges(cell)
+				addRegions(trackedChangeID(n+1, r)+"."+strconv.Itoa(j), deleted, inserted)
+			}
+		}
+	}
+
+	if regions == "" {
+		return ""
+	}
+
+	return "<text:tracked-changes>" + regions + "</text:tracked-changes>"
+}
+
+// automaticStyles ... generate the paragraph and text styles shared by every table, including
+// those of the titles of landscape tables, if there are any
+func (w *Writer) automaticStyles(hasLandscapeTables bool) string {
+
+	styles := "<style:style style:name=\"P1\" style:family=\"paragraph\" style:parent-style-name=\"Standard\">" +
+		"<style:paragraph-properties fo:break-before=\"page\" />" +
+		w.Theme.titleTextProperties() +
+		"</style:style>"
+
+	// the Landscape master page these refer to is only added to the
+	// styles of documents with landscape tables
+	if hasLandscapeTables {
+		styles += "<style:style style:name=\"P8\" style:family=\"paragraph\" style:parent-style-name=\"Standard\" style:master-page-name=\"Landscape\">" +
+			w.Theme.titleTextProperties() +
+			"</style:style>" +
+
+			"<style:style style:name=\"P9\" style:family=\"paragraph\" style:parent-style-name=\"Standard\" style:master-page-name=\"Standard\">" +
+			w.Theme.titleTextProperties() +
+			"</style:style>"
+	}
+
+	return styles + "<style:style style:name=\"P10\" style:family=\"paragraph\" style:parent-style-name=\"Standard\">" +
+		"<style:paragraph-properties fo:margin-top=\"8cm\" fo:margin-bottom=\"1cm\" fo:text-align=\"center\" style:justify-single-word=\"false\"/>" +
+		"<style:text-properties fo:font-size=\"24pt\" fo:font-weight=\"bold\" style:font-weight-asian=\"bold\" style:font-weight-complex=\"bold\"/>" +
+		"</style:style>" +
+
+		"<style:style style:name=\"P11\" style:family=\"paragraph\" style:parent-style-name=\"Standard\">" +
+		"<style:paragraph-properties fo:margin-bottom=\"0.5cm\" fo:text-align=\"center\" style:justify-single-word=\"false\"/>" +
+		"<style:text-properties fo:font-size=\"14pt\"/>" +
+		"</style:style>" +
+
+		"<style:style style:name=\"P12\" style:family=\"paragraph\" style:parent-style-name=\"Standard\">" +
+		"<style:paragraph-properties fo:break-after=\"page\"/>" +
+		"</style:style>" +
+
+		"<style:style style:name=\"P13\" style:family=\"paragraph\" style:parent-style-name=\"Standard\">" +
+		"<style:paragraph-properties fo:margin-top=\"0.2cm\"/>" +
+		"<style:text-properties fo:font-size=\"7pt\" fo:color=\"#555555\" style:font-size-asian=\"7pt\" style:font-size-complex=\"7pt\"/>" +
+		"</style:style>" +
+
+		"<style:style style:name=\"T1\" style:family=\"text\">" +
+		"<style:text-properties fo:font-weight=\"bold\" style:font-weight-asian=\"bold\" style:font-weight-complex=\"bold\"/>" +
+		"</style:style>" +
+
+		"<style:style style:name=\"T2\" style:family=\"text\">" +
+		"<style:text-properties fo:font-style=\"italic\" style:font-style-asian=\"italic\" style:font-style-complex=\"italic\"/>" +
+		"</style:style>" +
+
+		"<style:style style:name=\"T3\" style:family=\"text\">" +
+		"<style:text-properties fo:background-color=\"#c6efce\"/>" +
+		"</style:style>" +
+
+		"<style:style style:name=\"T4\" style:family=\"text\">" +
+		"<style:text-properties fo:background-color=\"#ffc7ce\" style:text-line-through-style=\"solid\" style:text-line-through-type=\"single\"/>" +
+		"</style:style>" +
+
+		"<style:style style:name=\"T5\" style:family=\"text\">" +
+		"<style:text-properties fo:background-color=\"#ffeb9c\"/>" +
+		"</style:style>" +
+
+		"<style:style style:name=\"P2\" style:family=\"paragraph\" style:parent-style-name=\"Footer\">" +
+		"<style:paragraph-properties fo:text-align=\"end\" style:justify-single-word=\"false\"/>" +
+		"</style:style>" +
+
+		"<style:style style:name=\"P3\" style:family=\"paragraph\" style:parent-style-name=\"Table_20_Contents\">" +
+		"<style:paragraph-properties fo:text-align=\"center\" style:justify-single-word=\"false\"/>" +
+		"</style:style>" +
+
+		"<style:style style:name=\"P4\" style:family=\"paragraph\" style:parent-style-name=\"Table_20_Contents\">" +
+		"<style:text-properties fo:font-weight=\"bold\" style:font-weight-asian=\"bold\" style:font-weight-complex=\"bold\"/>" +
+		"</style:style>" +
+
+		"<style:style style:name=\"P5\" style:family=\"paragraph\" style:parent-style-name=\"Table_20_Contents\">" +
+		"<style:paragraph-properties fo:text-align=\"center\" style:justify-single-word=\"false\"/>" +
+		"<style:text-properties fo:font-weight=\"bold\" style:font-weight-asian=\"bold\" style:font-weight-complex=\"bold\"/>" +
+		"</style:style>" +
+
+		"<style:style style:name=\"P6\" style:family=\"paragraph\" style:parent-style-name=\"Table_20_Contents\">" +
+		"<style:paragraph-properties fo:text-align=\"end\" style:justify-single-word=\"false\"/>" +
+		"</style:style>" +
+
+		"<style:style style:name=\"P7\" style:family=\"paragraph\" style:parent-style-name=\"Table_20_Contents\">" +
+		"<style:paragraph-properties fo:text-align=\"end\" style:justify-single-word=\"false\"/>" +
+		"<style:text-properties fo:font-weight=\"bold\" style:font-weight-asian=\"bold\" style:font-weight-complex=\"bold\"/>" +
+		"</style:style>"
+}
+
+// indentStyles ... generate the paragraph styles of each level of row indentation, given the
+// indent, in cm, of each level
+func indentStyles(maxLevel int, indentPerLevel float64) string {
+
+	styles := ""
+
+	// each level receives a proportionally larger paragraph indent, in
+	// both a regular and a bold (header row) flavour
+	for level := 1; level <= maxLevel; level++ {
+
+		levelStr := strconv.Itoa(level)
+		marginStr := strconv.FormatFloat(float64(level)*indentPerLevel, 'f', 3, 64) + "cm"
+
+		styles += "<style:style style:name=\"Indent" + levelStr + "\" style:family=\"paragraph\" style:parent-style-name=\"Table_20_Contents\">" +
+			"<style:paragraph-properties fo:margin-left=\"" + marginStr + "\" fo:text-indent=\"0cm\" style:auto-text-indent=\"false\"/>" +
+			"</style:style>"
+
+		styles += "<style:style style:name=\"IndentBold" + levelStr + "\" style:family=\"paragraph\" style:parent-style-name=\"Table_20_Contents\">" +
+			"<style:paragraph-properties fo:margin-left=\"" + marginStr + "\" fo:text-indent=\"0cm\" style:auto-text-indent=\"false\"/>" +
+			"<style:text-properties fo:font-weight=\"bold\" style:font-weight-asian=\"bold\" style:font-weight-complex=\"bold\"/>" +
+			"</style:style>"
+	}
+
+	return styles
+}
+
+// meta ... generate the meta.xml of the document from its metadata
+func (w *Writer) meta() (string, error) {
+
+	start := strings.Index(w.template.meta, "<office:meta>")
+	end := strings.Index(w.template.meta, "</office:meta>")
+	if start == -1 || end == -1 {
+		return "", fmt.Errorf("meta() --> malformed template, consider replacing the ODT template")
+	}
+
+	created := w.created().Format("2006-01-02T15:04:05")
+
+	properties := ""
+	if w.Metadata.Generator != "" {
+		properties += "<meta:generator>" + html.EscapeString(w.Metadata.Generator) + "</meta:generator>"
+	}
+	if w.Metadata.Title != "" {
+		properties += "<dc:title>" + html.EscapeString(w.Metadata.Title) + "</dc:title>"
+	}
+	if w.Metadata.Subject != "" {
+		properties += "<dc:subject>" + html.EscapeString(w.Metadata.Subject) + "</dc:subject>"
+	}
+	for _, keyword := range w.Metadata.Keywords {
+		properties += "<meta:keyword>" + html.EscapeString(keyword) + "</meta:keyword>"
+	}
+	if w.Metadata.Author != "" {
+		properties += "<meta:initial-creator>" + html.EscapeString(w.Metadata.Author) + "</meta:initial-creator>"
+		properties += "<dc:creator>" + html.EscapeString(w.Metadata.Author) + "</dc:creator>"
+	}
+	properties += "<meta:creation-date>" + created + "</meta:creation-date>"
+	properties += "<dc:date>" + created + "</dc:date>"
+
+	return w.template.meta[:start] + "<office:meta>" + properties + w.template.meta[end:], nil
+}
+
+// created ... creation time of the document, being now if not given
+func (w *Writer) created() time.Time {
+
+	if w.Metadata.Created.IsZero() {
+		w.Metadata.Created = time.Now()
+	}
+
+	return w.Metadata.Created
+}
+
+// styles ... generate the styles.xml of the document, with its page headers and footers
+func (w *Writer) styles(hasLandscapeTables bool) string {
+
+	styles := w.template.styles
+
+	//
+	// Append the landscape page styles, if any table requires them
+	//
+
+	portrait := w.template.page
+	landscape := portrait.landscape()
+
+	if hasLandscapeTables {
+
+		styles = strings.Replace(styles, "</office:automatic-styles><office:master-styles>",
+			"<style:page-layout style:name=\"Mpm2\">"+
+				"<style:page-layout-properties fo:page-width=\""+cm(landscape.width)+"\" fo:page-height=\""+cm(landscape.height)+"\" style:num-format=\"1\" "+
+				"style:print-orientation=\"landscape\" fo:margin-top=\""+cm(landscape.marginTop)+"\" fo:margin-bottom=\""+cm(landscape.marginBottom)+"\" "+
+				"fo:margin-left=\""+cm(landscape.marginLeft)+"\" fo:margin-right=\""+cm(landscape.marginRight)+"\" "+
+				"style:writing-mode=\"lr-tb\" style:footnote-max-height=\"0cm\"/>"+
+				"<style:header-style/>"+
+				"<style:footer-style/>"+
+				"</style:page-layout>"+
+				"</office:automatic-styles><office:master-styles>", -1)
+
+		styles = strings.Replace(styles, "</office:master-styles>",
+			"<style:master-page style:name=\"Landscape\" style:page-layout-name=\"Mpm2\">"+
+				w.headerAndFooter(true)+
+				"</style:master-page>"+
+				"</office:master-styles>", -1)
+	}
+
+	//
+	// Append the new header and footer styles, whose centre and right
+	// sections are tabbed to the middle and end of the printable area
+	//
+
+	styles = strings.Replace(styles, "</style:style><text:outline-style style:name=\"Outline\">",
+		"</style:style>"+
+			"<style:style style:name=\"Footer\" style:family=\"paragraph\" style:parent-style-name=\"Standard\" style:class=\"extra\">"+
+			"<style:paragraph-properties text:number-lines=\"false\" text:line-number=\"0\">"+
+			"<style:tab-stops>"+
+			tabStops(portrait)+
+			"</style:tab-stops>"+
+			"</style:paragraph-properties>"+
+			"</style:style>"+
+			"<style:style style:name=\"Header\" style:family=\"paragraph\" style:parent-style-name=\"Standard\" style:class=\"extra\">"+
+			"<style:paragraph-properties text:number-lines=\"false\" text:line-number=\"0\">"+
+			"<style:tab-stops>"+
+			tabStops(portrait)+
+			"</style:tab-stops>"+
+			"</style:paragraph-properties>"+
+			"</style:style>"+
+			"<style:style style:name=\"Table_20_Contents\" style:display-name=\"Table Contents\" style:family=\"paragraph\" style:parent-style-name=\"Standard\" style:class=\"extra\">"+
+			"<style:paragraph-properties text:number-lines=\"false\" text:line-number=\"0\"/>"+
+			w.Theme.bodyTextProperties()+
+			"</style:style>"+
+			"<style:style style:name=\"Table_20_index_20_heading\" style:display-name=\"Table index heading\" style:family=\"paragraph\" style:parent-style-name=\"Heading\" style:class=\"index\">"+
+			"<style:paragraph-properties fo:margin-left=\"0cm\" fo:margin-right=\"0cm\" fo:text-indent=\"0cm\" style:auto-text-indent=\"false\" text:number-lines=\"false\" text:line-number=\"0\"/>"+
+			"<style:text-properties fo:font-size=\"16pt\" fo:font-weight=\"bold\" style:font-size-asian=\"16pt\" style:font-weight-asian=\"bold\" style:font-size-complex=\"16pt\" style:font-weight-complex=\"bold\"/>"+
+			"</style:style>"+
+			"<style:style style:name=\"Table_20_index_20_1\" style:display-name=\"Table index 1\" style:family=\"paragraph\" style:parent-style-name=\"Index\" style:class=\"index\">"+
+			"<style:paragraph-properties fo:margin-left=\"0cm\" fo:margin-right=\"0cm\" fo:text-indent=\"0cm\" style:auto-text-indent=\"false\">"+
+			"<style:tab-stops>"+
+			"<style:tab-stop style:position=\""+cm(portrait.printableWidth())+"\" style:type=\"right\" style:leader-style=\"dotted\" style:leader-text=\".\"/>"+
+			"</style:tab-stops>"+
+			"</style:paragraph-properties>"+
+			"</style:style>"+
+			"<text:outline-style style:name=\"Outline\">", -1)
+
+	// MP1 and MP2 are the portrait and landscape footers, MP3 and MP4 the
+	// same for the headers, whose left|centre|right sections are tabbed
+	styles = strings.Replace(styles, "<office:automatic-styles><style:page-layout style:name=\"Mpm1\">",
+		"<office:automatic-styles>"+
+			"<style:style style:name=\"MP1\" style:family=\"paragraph\" style:parent-style-name=\"Footer\"/>"+
+			"<style:style style:name=\"MP2\" style:family=\"paragraph\" style:parent-style-name=\"Footer\">"+
+			"<style:paragraph-properties>"+
+			"<style:tab-stops>"+
+			tabStops(landscape)+
+			"</style:tab-stops>"+
+			"</style:paragraph-properties>"+
+			"</style:style>"+
+			"<style:style style:name=\"MP3\" style:family=\"paragraph\" style:parent-style-name=\"Header\"/>"+
+			"<style:style style:name=\"MP4\" style:family=\"paragraph\" style:parent-style-name=\"Header\">"+
+			"<style:paragraph-properties>"+
+			"<style:tab-stops>"+
+			tabStops(landscape)+
+			"</style:tab-stops>"+
+			"</style:paragraph-properties>"+
+			"</style:style>"+
+			"<style:page-layout style:name=\"Mpm1\">", -1)
+
+	if w.Header != "" {
+		styles = strings.Replace(styles, "<style:header-style/>",
+			"<style:header-style>"+
+				"<style:header-footer-properties fo:min-height=\"0cm\" fo:margin-bottom=\"0.499cm\"/>"+
+				"</style:header-style>", -1)
+	}
+
+	if w.Footer != "" {
+		styles = strings.Replace(styles, "<style:footer-style/>",
+			"<style:footer-style>"+
+				"<style:header-footer-properties fo:min-height=\"0cm\" fo:margin-top=\"0.499cm\"/>"+
+				"</style:footer-style>", -1)
+	}
+
+	return strings.Replace(styles, "<style:master-page style:name=\"Standard\" style:page-layout-name=\"Mpm1\"/>",
+		"<style:master-page style:name=\"Standard\" style:page-layout-name=\"Mpm1\">"+
+			w.headerAndFooter(false)+
+			"</style:master-page>", -1)
+}
+
+// tabStops ... the tab stops of the centre and right sections of a page header or footer
+func tabStops(page pageLayout) string {
+	return "<style:tab-stop style:position=\"" + cm(page.printableWidth()/2) + "\" style:type=\"center\"/>" +
+		"<style:tab-stop style:position=\"" + cm(page.printableWidth()) + "\" style:type=\"right\"/>"
+}
+
+// cm ... format a length in cm
+func cm(length float64) string {
+	return strconv.FormatFloat(length, 'f', 3, 64) + "cm"
+}
+
+// headerAndFooter ... generate the ODT page header and footer of a master page
+func (w *Writer) headerAndFooter(landscape bool) string {
+
+	headerStyle := "MP3"
+	footerStyle := "MP1"
+	if landscape {
+		headerStyle = "MP4"
+		footerStyle = "MP2"
+	}
+
+	result := ""
+	if w.Header != "" {
+		result += "<style:header>" + w.headerFooterParagraph(w.Header, headerStyle) + "</style:header>"
+	}
+	if w.Footer != "" {
+		result += "<style:footer>" + w.headerFooterParagraph(w.Footer, footerStyle) + "</style:footer>"
+	}
+
+	return result
+}
+
+// headerFooterParagraph ... turn a "left|centre|right" header or footer template into an ODT paragraph
+func (w *Writer) headerFooterParagraph(template string, styleName string) string {
+
+	created := w.created()
+
+	content := ""
+	for i, section := range strings.SplitN(template, "|", 3) {
+
+		if i > 0 {
+			content += "<text:tab/>"
+		}
+
+		section = html.EscapeString(section)
+		section = strings.Replace(section, "{page}", "<text:page-number text:select-page=\"current\">1</text:page-number>", -1)
+		section = strings.Replace(section, "{pages}", "<text:page-count>1</text:page-count>", -1)
+		section = strings.Replace(section, "{date}", "<text:date text:fixed=\"true\" text:date-value=\""+
+			created.Format("2006-01-02")+"\">"+created.Format("January 2, 2006")+"</text:date>", -1)
+		section = strings.Replace(section, "{report}", "<text:title>"+html.EscapeString(w.Metadata.Title)+"</text:title>", -1)
+		section = strings.Replace(section, "{source}", "<text:variable-get text:name=\"SourceFile\" text:display=\"value\"/>", -1)
+
+		content += section
+	}
+
+	return "<text:p text:style-name=\"" + styleName + "\">" + content + "</text:p>"
+}
+
+// titlePage ... generate an ODT title page from the document metadata
+func (w *Writer) titlePage() string {
+
+	titlePage := "<text:p text:style-name=\"P10\">" + html.EscapeString(w.Metadata.Title) + "</text:p>"
+	if w.Metadata.Subject != "" {
+		titlePage += "<text:p text:style-name=\"P11\">" + html.EscapeString(w.Metadata.Subject) + "</text:p>"
+	}
+	if w.Metadata.Author != "" {
+		titlePage += "<text:p text:style-name=\"P11\">" + html.EscapeString(w.Metadata.Author) + "</text:p>"
+	}
+	titlePage += "<text:p text:style-name=\"P11\">" + w.created().Format("January 2, 2006") + "</text:p>"
+
+	// end the title page with a page break
+	return titlePage + "<text:p text:style-name=\"P12\"/>"
+}
+
+// tableIndex ... generate an ODT list of tables from the table captions
+func (w *Writer) tableIndex(pagesBeforeIndex int) string {
+
+	entriesPerPage := w.IndexEntriesPerPage
+	if entriesPerPage < 1 {
+		entriesPerPage = 1
+	}
+
+	// every table starts on a new page after the list itself, so
+	// estimate the page numbers until LibreOffice updates the index
+	indexPages := pagesBeforeIndex + 1 + len(w.tables)/entriesPerPage
+
+	entries := ""
+	for i, t := range w.tables {
+		if t.Table == nil {
+			continue
+		}
+		entries += "<text:p text:style-name=\"Table_20_index_20_1\">" + html.EscapeString(t.Caption(w.caption())) +
+			"<text:tab/>" + strconv.Itoa(indexPages+i+1) + "</text:p>"
+	}
+
+	indexTitle := html.EscapeString(w.IndexTitle)
+
+	return "<text:table-index text:name=\"Table index1\">" +
+		"<text:table-index-source text:caption-sequence-name=\"Table\" text:caption-sequence-format=\"text\">" +
+		"<text:index-title-template text:style-name=\"Table_20_index_20_heading\">" + indexTitle + "</text:index-title-template>" +
+		"<text:table-index-entry-template text:style-name=\"Table_20_index_20_1\">" +
+		"<text:index-entry-text/>" +
+		"<text:index-entry-tab-stop style:type=\"right\" style:leader-char=\".\"/>" +
+		"<text:index-entry-page-number/>" +
+		"</text:table-index-entry-template>" +
+		"</text:table-index-source>" +
+		"<text:index-body>" +
+		"<text:index-title text:name=\"Table index1_Head\">" +
+		"<text:p text:style-name=\"Table_20_index_20_heading\">" + indexTitle + "</text:p>" +
+		"</text:index-title>" +
+		entries +
+		"</text:index-body>" +
+		"</text:table-index>"
+}
+
+// countingWriter ... tally the bytes passed on to the underlying writer
+type countingWriter struct {
+	writer io.Writer
+	count  int64
+}
+
+// Write ... pass the given bytes on, counting them
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.writer.Write(p)
+	c.count += int64(n)
+	return n, err
+}
@@ -0,0 +1,223 @@
+package odt
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/rbisewski/scaffolding/rosewood"
+)
+
+const (
+	oddsTable = `
+Odds ratios & intervals
+---
+Variable | OR | p
+Age | 1.23 (0.98, 1.54) | 0.07
+Smoker | 2.10 (1.40, 3.15) | <0.001
+`
+)
+
+func TestWriteTo(t *testing.T) {
+	template, err := ReadTemplate("../templates/odt_blank_template")
+	if err != nil {
+		t.Fatalf("ReadTemplate() error = %v", err)
+	}
+	table, err := rosewood.Parse(strings.NewReader(oddsTable))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	table.Number = 1
+
+	tests := []struct {
+		name    string
+		tables  int
+		options TableOptions
+		wants   []string
+		lacks   []string
+	}{
+		{"no tables", 0, TableOptions{}, []string{"<office:automatic-styles>"}, nil},
+		{"one table", 1, TableOptions{}, []string{"Odds ratios &amp; intervals", "Table1.B.Tab", "office:value=\"0.07\""},
+			[]string{"style:master-page-name=\"Landscape\""}},
+		{"many tables", 3, TableOptions{}, []string{"table:name=\"Table3\""}, nil},
+		{"landscape table", 1, TableOptions{Landscape: true},
+			[]string{"style:master-page-name=\"Landscape\"", "<text:p text:style-name=\"P8\">"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := NewWriter(template)
+			for i := 0; i < tt.tables; i++ {
+				writer.AddTable(table, tt.options)
+			}
+
+			checkContent(t, writeContentXML(t, writer), tt.wants, tt.lacks)
+		})
+	}
+}
+
+func TestAddTableNil(t *testing.T) {
+	if _, err := NewWriter(&Template{}).AddTable(nil).WriteTo(ioutil.Discard); err == nil {
+		t.Errorf("WriteTo() of a nil table ought to fail")
+	}
+}
+
+func TestWriteToHeaderRows(t *testing.T) {
+	template, err := ReadTemplate("../templates/odt_blank_template")
+	if err != nil {
+		t.Fatalf("ReadTemplate() error = %v", err)
+	}
+	table, err := rosewood.ParseJSON(strings.NewReader(`{"title": "Grouped",
+		"headers": [[{"text": ""}, {"text": "Both groups", "span": 2}], [{"text": "Variable"}, {"text": "Cases"}, {"text": "Controls"}]],
+		"rows": [[{"text": "Age"}, {"text": "45.2"}, {"text": "44.0"}], [{"text": "BMI"}, {"text": "27.1"}, {"text": "26.4"}]]}`))
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+
+	// the header rows are ruled above and below, the sub-headings being
+	// left out of the alignment of the numeric columns beneath them
+	tests := []struct {
+		name  string
+		theme string
+		wants []string
+		lacks []string
+	}{
+		{"two header rows", "apa", []string{
+			"<table:table-row><table:table-cell table:style-name=\"Table1.A6\"",
+			"<table:table-row><table:table-cell table:style-name=\"Table1.A8\"",
+			"<table:table-row><table:table-cell table:style-name=\"Table1.A2\"",
+			"table:number-columns-spanned=\"2\"",
+			"Table1.B.Tab",
+		}, []string{"<table:table-row><table:table-cell table:style-name=\"Table1.A1\""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := NewWriter(template)
+			writer.Theme = Presets[tt.theme]
+			writer.AddTable(table)
+
+			checkContent(t, writeContentXML(t, writer), tt.wants, tt.lacks)
+		})
+	}
+}
+
+func TestWriteToEmptyTable(t *testing.T) {
+	empty := rosewood.Compare(nil, nil).Redline()
+	if _, err := NewWriter(&Template{}).AddTable(empty).WriteTo(ioutil.Discard); err == nil {
+		t.Errorf("WriteTo() of a table of no columns ought to fail")
+	}
+}
+
+func TestAddPlaceholder(t *testing.T) {
+	template, err := ReadTemplate("../templates/odt_blank_template")
+	if err != nil {
+		t.Fatalf("ReadTemplate() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		index bool
+		wants []string
+		lacks []string
+	}{
+		{"without index", false, []string{"Table 1 failed to convert: &lt;missing&gt;"}, nil},
+		{"with index", true, []string{"Table 1 failed to convert: &lt;missing&gt;"},
+			[]string{"Table_20_index_20_1\">Table 1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := NewWriter(template)
+			writer.Index = tt.index
+			writer.AddPlaceholder("Table 1 failed to convert: <missing>")
+
+			checkContent(t, writeContentXML(t, writer), tt.wants, tt.lacks)
+		})
+	}
+}
+
+func TestTrackChanges(t *testing.T) {
+	template, err := ReadTemplate("../templates/odt_blank_template")
+	if err != nil {
+		t.Fatalf("ReadTemplate() error = %v", err)
+	}
+	old, err := rosewood.Parse(strings.NewReader(oddsTable))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	new, err := rosewood.Parse(strings.NewReader(strings.Replace(strings.Replace(oddsTable, "0.07", "0.06", 1),
+		"Odds ratios", "Adjusted odds ratios", 1)))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		trackChanges bool
+		wants        []string
+		lacks        []string
+	}{
+		{"tracked", true, []string{
+			"<office:text><text:tracked-changes><text:changed-region text:id=\"ct1.t.0d\">",
+			"<text:changed-region text:id=\"ct1.1.2d\"><text:deletion>",
+			"<text:p>0.07</text:p>",
+			"<text:change text:change-id=\"ct1.1.2d\"/>",
+			"<text:change-start text:change-id=\"ct1.1.2i\"/>0.06<text:change-end text:change-id=\"ct1.1.2i\"/>",
+			"<text:changed-region text:id=\"ct1.t.0d\"><text:deletion>",
+			"<text:p>Odds ratios &amp; intervals</text:p>",
+			"<text:change-start text:change-id=\"ct1.t.0i\"/>Adjusted odds ratios &amp; intervals<text:change-end",
+		}, nil},
+		{"untracked", false, []string{"Adjusted odds ratios &amp; intervals", "0.06"},
+			[]string{"<text:tracked-changes>", "<text:change-start"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := NewWriter(template)
+			writer.TrackChanges = tt.trackChanges
+			writer.AddTable(rosewood.Compare(old, new).Redline())
+
+			checkContent(t, writeContentXML(t, writer), tt.wants, tt.lacks)
+		})
+	}
+}
+
+// writeContentXML ... write the document of a writer, returning its content.xml
+func writeContentXML(t *testing.T, writer *Writer) string {
+	t.Helper()
+
+	var output bytes.Buffer
+	n, err := writer.WriteTo(&output)
+	if err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	if n != int64(output.Len()) {
+		t.Errorf("WriteTo() = %d, wrote %d bytes", n, output.Len())
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(output.Bytes()), int64(output.Len()))
+	if err != nil {
+		t.Fatalf("WriteTo() wrote an invalid zip: %v", err)
+	}
+	content, err := readFile(reader.File, "content.xml")
+	if err != nil {
+		t.Fatalf("WriteTo() wrote no content.xml: %v", err)
+	}
+
+	return content
+}
+
+// checkContent ... check that content.xml has each of the wanted strings and none of the lacking
+func checkContent(t *testing.T, content string, wants, lacks []string) {
+	t.Helper()
+
+	for _, want := range wants {
+		if !strings.Contains(content, want) {
+			t.Errorf("WriteTo() content.xml lacks %q", want)
+		}
+	}
+	for _, lack := range lacks {
+		if strings.Contains(content, lack) {
+			t.Errorf("WriteTo() content.xml has %q", lack)
+		}
+	}
+}
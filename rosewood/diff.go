@@ -0,0 +1,273 @@
+package rosewood
+
+import (
+	"strconv"
+	"strings"
+)
+
+// kinds of change between two versions of a table
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Diff ... the rows of two versions of a table, matched by their labels
+type Diff struct {
+	Old *Table
+	New *Table
+
+	// rows in the order of the new version, with each removed row placed
+	// after the row it followed in the old version
+	Rows []RowDiff
+}
+
+// RowDiff ... a row of either or both versions of a table, and how it changed
+type RowDiff struct {
+
+	// text of the first cell, preceded by those of the rows it is nested
+	// under, e.g. "Sex > Female"; repeated labels are numbered, e.g. "Age #2"
+	Label string
+
+	// whether the row is one of the header rows
+	Header bool
+
+	// one of the Change constants, or blank if the row is unchanged
+	Change string
+
+	// cells of the row in the old and new versions, either being nil if
+	// the row was added or removed
+	Old []Cell
+	New []Cell
+
+	// columns whose text changed, if the row is in both versions
+	Columns []int
+}
+
+// Compare ... match the rows of two versions of a table by their labels, and determine which were
+// added, removed or changed; a nil table is taken to be one of no rows
+func Compare(old *Table, new *Table) *Diff {
+
+	if old == nil {
+		old = &Table{}
+	}
+	if new == nil {
+		new = &Table{}
+	}
+
+	d := &Diff{Old: old, New: new}
+
+	// header rows are compared column by column, in order, having no
+	// label but their position after the first
+	for i := 0; i < len(old.Headers) || i < len(new.Headers); i++ {
+
+		var oldCells, newCells []Cell
+		if i < len(old.Headers) {
+			oldCells = old.Headers[i]
+		}
+		if i < len(new.Headers) {
+			newCells = new.Headers[i]
+		}
+
+		label := ""
+		if i > 0 {
+			label = "#" + strconv.Itoa(i+1)
+		}
+		d.Rows = append(d.Rows, compareRow(label, true, oldCells, newCells))
+	}
+
+	oldLabels := rowLabels(old.Rows)
+	newLabels := rowLabels(new.Rows)
+
+	oldIndexes := make(map[string]int)
+	for i, label := range oldLabels {
+		oldIndexes[label] = i
+	}
+	newIndexes := make(map[string]int)
+	for j, label := range newLabels {
+		newIndexes[label] = j
+	}
+
+	// rows of the old version before next have been placed
+	next := 0
+	placeRemoved := func(until int) {
+		for ; next < until; next++ {
+			if _, ok := newIndexes[oldLabels[next]]; !ok {
+				d.Rows = append(d.Rows, compareRow(oldLabels[next], false, old.Rows[next], nil))
+			}
+		}
+	}
+
+	for j, cells := range new.Rows {
+
+		i, ok := oldIndexes[newLabels[j]]
+		if !ok {
+			d.Rows = append(d.Rows, compareRow(newLabels[j], false, nil, cells))
+			continue
+		}
+
+		placeRemoved(i)
+		if next < i+1 {
+			next = i + 1
+		}
+
+		d.Rows = append(d.Rows, compareRow(newLabels[j], false, old.Rows[i], cells))
+	}
+	placeRemoved(len(old.Rows))
+
+	return d
+}
+
+// Changed ... whether the title or any of the rows of the table changed
+func (d *Diff) Changed() bool {
+
+	if d.Old.Title != d.New.Title {
+		return true
+	}
+	for _, row := range d.Rows {
+		if row.Change != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Redline ... the new version of the table, including the removed rows, with each cell marked
+// with how it changed, and the old title kept if the title changed; removed tables keep their old
+// title
+func (d *Diff) Redline() *Table {
+
+	redline := &Table{
+		Title:   d.New.Title,
+		Number:  d.New.Number,
+		Columns: d.New.Columns,
+		Source:  d.New.Source,
+	}
+	if redline.Title == "" {
+		redline.Title = d.Old.Title
+	} else if d.Old.Title != "" && d.Old.Title != d.New.Title {
+		redline.OldTitle = d.Old.Title
+	}
+	if d.Old.Columns > redline.Columns {
+		redline.Columns = d.Old.Columns
+	}
+
+	for _, row := range d.Rows {
+
+		var cells []Cell
+		switch row.Change {
+		case ChangeAdded:
+			cells = markCells(row.New, ChangeAdded)
+		case ChangeRemoved:
+			cells = markCells(row.Old, ChangeRemoved)
+		default:
+			cells = markCells(row.New, "")
+			for _, j := range row.Columns {
+				for len(cells) <= j {
+					cells = append(cells, Cell{})
+				}
+				cells[j].Change = ChangeChanged
+				if j < len(row.Old) {
+					cells[j].OldText = row.Old[j].Text
+				}
+			}
+		}
+
+		if row.Header {
+			redline.Headers = append(redline.Headers, cells)
+		} else {
+			redline.Rows = append(redline.Rows, cells)
+		}
+	}
+
+	return redline
+}
+
+// compareRow ... determine how a row changed between versions, either of which may be nil
+func compareRow(label string, header bool, old []Cell, new []Cell) RowDiff {
+
+	row := RowDiff{Label: label, Header: header, Old: old, New: new}
+
+	switch {
+	case old == nil:
+		row.Change = ChangeAdded
+	case new == nil:
+		row.Change = ChangeRemoved
+	default:
+		for j := 0; j < len(old) || j < len(new); j++ {
+
+			oldText, newText := "", ""
+			if j < len(old) {
+				oldText = old[j].Text
+			}
+			if j < len(new) {
+				newText = new[j].Text
+			}
+
+			if oldText != newText {
+				row.Columns = append(row.Columns, j)
+			}
+		}
+		if len(row.Columns) > 0 {
+			row.Change = ChangeChanged
+		}
+	}
+
+	return row
+}
+
+// rowLabels ... label each row by its first cell and those of the rows it is nested under,
+// numbering repeated labels so that each is unique
+func rowLabels(rows [][]Cell) []string {
+
+	labels := make([]string, 0, len(rows))
+	seen := make(map[string]int)
+
+	// first cells of the rows the current one may be nested under, by
+	// their indentation level
+	parents := make([]string, 0)
+
+	for _, cells := range rows {
+
+		text, indent := "", 0
+		if len(cells) > 0 {
+			text, indent = cells[0].Text, cells[0].Indent
+		}
+
+		if indent < len(parents) {
+			parents = parents[:indent]
+		}
+		pieces := make([]string, 0, len(parents)+1)
+		for _, parent := range parents {
+			if parent != "" {
+				pieces = append(pieces, parent)
+			}
+		}
+		label := strings.Join(append(pieces, text), " > ")
+		for len(parents) < indent {
+			parents = append(parents, "")
+		}
+		parents = append(parents, text)
+
+		seen[label]++
+		if seen[label] > 1 {
+			label += " #" + strconv.Itoa(seen[label])
+		}
+		labels = append(labels, label)
+	}
+
+	return labels
+}
+
+// markCells ... copy the cells of a row, marking each with the given change
+func markCells(cells []Cell, change string) []Cell {
+
+	marked := make([]Cell, len(cells))
+	for i, cell := range cells {
+		marked[i] = cell
+		marked[i].Change = change
+	}
+
+	return marked
+}
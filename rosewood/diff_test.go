@@ -0,0 +1,127 @@
+package rosewood
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name    string
+		new     string
+		changes []string
+		labels  []string
+	}{
+		{"unchanged", baselineTable, nil, nil},
+		{"changed value", strings.Replace(baselineTable, "45.2", "46.0", 1),
+			[]string{ChangeChanged}, []string{"Age"}},
+		{"added row", baselineTable + "BMI | 27.1 | 26.4\n",
+			[]string{ChangeAdded}, []string{"BMI"}},
+		{"removed row", strings.Replace(baselineTable, "p-value | <0.001 | 0.25\n", "", 1),
+			[]string{ChangeRemoved}, []string{"p-value"}},
+		{"nested row", strings.Replace(baselineTable, "120 (55%)", "121 (55%)", 1),
+			[]string{ChangeChanged}, []string{"Sex > Female"}},
+		{"reordered rows", strings.Replace(strings.Replace(baselineTable, "p-value | <0.001 | 0.25\n", "", 1),
+			"Age |", "p-value | <0.001 | 0.25\nAge |", 1), nil, nil},
+		{"changed header", strings.Replace(baselineTable, "Cases", "Patients", 1),
+			[]string{ChangeChanged}, []string{""}},
+	}
+
+	old, err := Parse(strings.NewReader(baselineTable))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			new, err := Parse(strings.NewReader(tt.new))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			d := Compare(old, new)
+			changes := make([]string, 0)
+			labels := make([]string, 0)
+			for _, row := range d.Rows {
+				if row.Change != "" {
+					changes = append(changes, row.Change)
+					labels = append(labels, row.Label)
+				}
+			}
+
+			if strings.Join(changes, ",") != strings.Join(tt.changes, ",") ||
+				strings.Join(labels, ",") != strings.Join(tt.labels, ",") {
+				t.Errorf("Compare() changes = %v %v, want %v %v", changes, labels, tt.changes, tt.labels)
+			}
+			if d.Changed() != (len(tt.changes) > 0) {
+				t.Errorf("Changed() = %v", d.Changed())
+			}
+			if rows := len(d.Redline().Rows); rows < len(new.Rows) {
+				t.Errorf("Redline() has %d rows, want at least %d", rows, len(new.Rows))
+			}
+		})
+	}
+}
+
+func TestRedlineTitle(t *testing.T) {
+	tests := []struct {
+		name         string
+		old          string
+		new          string
+		wantTitle    string
+		wantOldTitle string
+	}{
+		{"unchanged", baselineTable, baselineTable, "Baseline characteristics", ""},
+		{"changed", baselineTable, strings.Replace(baselineTable, "Baseline", "Patient", 1),
+			"Patient characteristics", "Baseline characteristics"},
+		{"added", "", baselineTable, "Baseline characteristics", ""},
+		{"removed", baselineTable, "", "Baseline characteristics", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var old, new *Table
+			var err error
+			if tt.old != "" {
+				if old, err = Parse(strings.NewReader(tt.old)); err != nil {
+					t.Fatalf("Parse() error = %v", err)
+				}
+			}
+			if tt.new != "" {
+				if new, err = Parse(strings.NewReader(tt.new)); err != nil {
+					t.Fatalf("Parse() error = %v", err)
+				}
+			}
+
+			redline := Compare(old, new).Redline()
+			if redline.Title != tt.wantTitle || redline.OldTitle != tt.wantOldTitle {
+				t.Errorf("Redline() title = %q, %q, want %q, %q", redline.Title, redline.OldTitle, tt.wantTitle, tt.wantOldTitle)
+			}
+		})
+	}
+}
+
+func TestCompareHeaderRows(t *testing.T) {
+	old, err := ParseJSON(strings.NewReader(`{"headers": [[{"text": "Variable"}, {"text": "n"}]], "rows": [[{"text": "Age"}, {"text": "45"}]]}`))
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+	new, err := ParseJSON(strings.NewReader(`{"headers": [[{"text": "Variable"}, {"text": "Cases"}], [{"text": ""}, {"text": "n"}]],
+		"rows": [[{"text": "Age"}, {"text": "45"}]]}`))
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+
+	d := Compare(old, new)
+	changes := make([]string, 0)
+	for _, row := range d.Rows {
+		if row.Change != "" {
+			changes = append(changes, row.Change+" "+row.Label)
+		}
+	}
+	if strings.Join(changes, ",") != ChangeChanged+" ,"+ChangeAdded+" #2" {
+		t.Errorf("Compare() changes = %v", changes)
+	}
+	if headers := len(d.Redline().Headers); headers != 2 {
+		t.Errorf("Redline() has %d header rows, want 2", headers)
+	}
+}
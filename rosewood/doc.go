@@ -0,0 +1,9 @@
+/*
+Package rosewood parses Rosewood tables into a Table model.
+
+A Rosewood table is a title line, a header row and body rows, whose cells are
+separated by "|"; leading whitespace in the first cell denotes a nested row.
+
+	table, err := rosewood.Parse(file)
+*/
+package rosewood
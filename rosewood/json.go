@@ -0,0 +1,213 @@
+package rosewood
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// jsonTable ... the JSON form of a table
+type jsonTable struct {
+	Title     string         `json:"title"`
+	Number    int            `json:"number"`
+	Columns   int            `json:"columns"`
+	Headers   [][]jsonCell   `json:"headers"`
+	Rows      [][]jsonCell   `json:"rows"`
+	Footnotes []jsonFootnote `json:"footnotes"`
+	Source    *jsonSource    `json:"source,omitempty"`
+}
+
+// jsonCell ... the JSON form of a cell; if not given, bold is taken to be true of header cells
+type jsonCell struct {
+	Text   string `json:"text"`
+	Indent int    `json:"indent"`
+	Bold   *bool  `json:"bold"`
+	Span   int    `json:"span"`
+}
+
+// jsonFootnote ... the JSON form of a footnote
+type jsonFootnote struct {
+	Marker string `json:"marker"`
+	Text   string `json:"text"`
+}
+
+// jsonSource ... the JSON form of the source of a table
+type jsonSource struct {
+	Path     string    `json:"path"`
+	Checksum string    `json:"sha256"`
+	Modified time.Time `json:"modified"`
+}
+
+// MarshalJSON ... encode the table as an object of its title, number, columns, header rows, body
+// rows, footnotes and source, if known
+func (t *Table) MarshalJSON() ([]byte, error) {
+
+	table := jsonTable{
+		Title:     t.Title,
+		Number:    t.Number,
+		Columns:   t.Columns,
+		Headers:   make([][]jsonCell, 0, len(t.Headers)),
+		Rows:      make([][]jsonCell, 0, len(t.Rows)),
+		Footnotes: make([]jsonFootnote, 0, len(t.Footnotes)),
+	}
+
+	for _, cells := range t.Headers {
+		table.Headers = append(table.Headers, jsonCells(cells))
+	}
+	for _, cells := range t.Rows {
+		table.Rows = append(table.Rows, jsonCells(cells))
+	}
+	for _, footnote := range t.Footnotes {
+		table.Footnotes = append(table.Footnotes, jsonFootnote{Marker: footnote.Marker, Text: footnote.Text})
+	}
+	if t.Source != nil {
+		table.Source = &jsonSource{
+			Path:     t.Source.Path,
+			Checksum: t.Source.Checksum,
+			Modified: t.Source.Modified,
+		}
+	}
+
+	// cells such as "<0.001" are kept as they are, rather than escaped
+	// for embedding in HTML
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(table); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimRight(encoded.Bytes(), "\n"), nil
+}
+
+// jsonCells ... the JSON form of the cells of a row
+func jsonCells(cells []Cell) []jsonCell {
+
+	row := make([]jsonCell, 0, len(cells))
+	for _, cell := range cells {
+
+		span := cell.Span
+		if span < 1 {
+			span = 1
+		}
+
+		bold := cell.Bold
+		row = append(row, jsonCell{
+			Text:   cell.Text,
+			Indent: cell.Indent,
+			Bold:   &bold,
+			Span:   span,
+		})
+	}
+
+	return row
+}
+
+// IsJSON ... whether the contents of a file are a JSON table rather than a Rosewood one, i.e. they
+// are a valid JSON object
+func IsJSON(contents []byte) bool {
+	trimmed := bytes.TrimSpace(contents)
+	return len(trimmed) > 0 && trimmed[0] == '{' && json.Valid(trimmed)
+}
+
+// ParseJSON ... read a table in the JSON form written by MarshalJSON from the given reader
+func ParseJSON(r io.Reader) (*Table, error) {
+
+	if r == nil {
+		return nil, fmt.Errorf("ParseJSON() --> invalid input")
+	}
+
+	table := &Table{}
+	if err := json.NewDecoder(r).Decode(table); err != nil {
+		return nil, err
+	}
+
+	return table, nil
+}
+
+// UnmarshalJSON ... decode a table in the JSON form written by MarshalJSON; its number, columns
+// and source are ignored, being determined by the table itself and how it is read
+func (t *Table) UnmarshalJSON(data []byte) error {
+
+	var table jsonTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		return fmt.Errorf("UnmarshalJSON() --> %s", err)
+	}
+
+	// documents of several tables, as written by -format json, are told
+	// apart from tables by their list of tables
+	var document struct {
+		Tables json.RawMessage `json:"tables"`
+	}
+	if err := json.Unmarshal(data, &document); err == nil && document.Tables != nil {
+		return fmt.Errorf("UnmarshalJSON() --> document of tables given; only single table objects are " +
+			"accepted, such as an entry of its list of tables")
+	}
+
+	*t = Table{Title: strings.TrimSpace(table.Title)}
+
+	for _, cells := range table.Headers {
+		row, err := tableCells(cells, true)
+		if err != nil {
+			return err
+		}
+		t.Headers = append(t.Headers, row)
+	}
+	for _, cells := range table.Rows {
+		row, err := tableCells(cells, false)
+		if err != nil {
+			return err
+		}
+		t.Rows = append(t.Rows, row)
+	}
+
+	for _, footnote := range table.Footnotes {
+		t.Footnotes = append(t.Footnotes, Footnote{Marker: footnote.Marker, Text: footnote.Text})
+	}
+
+	for _, cells := range append(append([][]Cell{}, t.Headers...), t.Rows...) {
+		if columns := RowColumns(cells); columns > t.Columns {
+			t.Columns = columns
+		}
+	}
+	if t.Columns < 1 {
+		return fmt.Errorf("UnmarshalJSON() --> empty table given")
+	}
+
+	return nil
+}
+
+// tableCells ... the cells of a row given in JSON form
+func tableCells(cells []jsonCell, header bool) ([]Cell, error) {
+
+	row := make([]Cell, 0, len(cells))
+	for _, cell := range cells {
+
+		if cell.Indent < 0 || cell.Span < 0 {
+			return nil, fmt.Errorf("UnmarshalJSON() --> invalid cell: %s", cell.Text)
+		}
+
+		// a span of one column is kept as none, as Parse would give
+		span := cell.Span
+		if span == 1 {
+			span = 0
+		}
+
+		bold := header
+		if cell.Bold != nil {
+			bold = *cell.Bold
+		}
+
+		row = append(row, Cell{
+			Text:   strings.TrimSpace(cell.Text),
+			Indent: cell.Indent,
+			Bold:   bold,
+			Span:   span,
+		})
+	}
+
+	return row, nil
+}
@@ -0,0 +1,113 @@
+package rosewood
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		wants []string
+	}{
+		{"baseline table", baselineTable, []string{
+			`"title":"Baseline characteristics"`,
+			`"headers":[[{"text":"Variable","indent":0,"bold":true,"span":1}`,
+			`{"text":"Female","indent":1,"bold":false,"span":1}`,
+			`"footnotes":[]`,
+		}},
+		{"footnotes", baselineTable + "^a Mean (95% CI)\n", []string{
+			`"footnotes":[{"marker":"a","text":"Mean (95% CI)"}]`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := Parse(strings.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			table.Number = 2
+
+			encoded, err := json.Marshal(table)
+			if err != nil {
+				t.Fatalf("MarshalJSON() error = %v", err)
+			}
+			for _, want := range append(tt.wants, `"number":2`, `"columns":3`) {
+				if !strings.Contains(string(encoded), want) {
+					t.Errorf("MarshalJSON() = %s, lacks %s", encoded, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    *Table
+		wantErr bool
+	}{
+		{"header bold by default",
+			`{"title": " Outcomes ", "headers": [[{"text": "Outcome"}, {"text": "n", "bold": false}]],
+			"rows": [[{"text": "Death"}, {"text": "12"}], [{"text": "Not reported", "span": 2}]]}`,
+			&Table{Title: "Outcomes", Columns: 2,
+				Headers: [][]Cell{{{Text: "Outcome", Bold: true}, {Text: "n"}}},
+				Rows: [][]Cell{
+					{{Text: "Death"}, {Text: "12"}},
+					{{Text: "Not reported", Span: 2}},
+				}}, false},
+		{"grouped header rows", `{"headers": [[{"text": ""}, {"text": "Both groups", "span": 2}],
+			[{"text": "Variable"}, {"text": "Cases"}, {"text": "Controls"}]],
+			"rows": [[{"text": "Age"}, {"text": "45"}, {"text": "44"}]], "footnotes": [{"marker": "a", "text": "x"}]}`,
+			&Table{Columns: 3,
+				Headers: [][]Cell{
+					{{Bold: true}, {Text: "Both groups", Bold: true, Span: 2}},
+					{{Text: "Variable", Bold: true}, {Text: "Cases", Bold: true}, {Text: "Controls", Bold: true}},
+				},
+				Rows:      [][]Cell{{{Text: "Age"}, {Text: "45"}, {Text: "44"}}},
+				Footnotes: []Footnote{{Marker: "a", Text: "x"}}}, false},
+		{"empty table", `{"title": "Outcomes"}`, nil, true},
+		{"negative span", `{"rows": [[{"text": "a", "span": -1}]]}`, nil, true},
+		{"not a table", `{"rows": "a"}`, nil, true},
+		{"document of tables", `{"generator": "scaffolding", "tables": [{"headers": [[{"text": "a"}]]}]}`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseJSON(strings.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJSONRoundTrip(t *testing.T) {
+	table, err := Parse(strings.NewReader(baselineTable + "^a Mean (95% CI)\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	encoded, err := json.Marshal(table)
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	if !IsJSON(encoded) {
+		t.Fatalf("IsJSON(%s) = false", encoded)
+	}
+
+	got, err := ParseJSON(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, table) {
+		t.Errorf("ParseJSON() = %+v, want %+v", got, table)
+	}
+}
@@ -0,0 +1,161 @@
+package rosewood
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// severities of the problems found by Lint
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Problem ... an issue found in a Rosewood table
+type Problem struct {
+
+	// line of the table the problem is on, counting from 1, or 0 if it is
+	// of the table as a whole
+	Line int `json:"line,omitempty"`
+
+	// short name of the check that found it, e.g. "columns"
+	Rule string `json:"rule"`
+
+	// SeverityError or SeverityWarning
+	Severity string `json:"severity"`
+
+	Message string `json:"message"`
+}
+
+// Lint ... check the contents of a Rosewood file for problems the parser lets through, such as
+// rows of the wrong number of columns or footnote markers without a footnote; empty files have
+// none, being converted to nothing
+func Lint(contents []byte) []Problem {
+
+	problems := make([]Problem, 0)
+	if len(contents) == 0 {
+		return problems
+	}
+	add := func(line int, rule string, severity string, format string, a ...interface{}) {
+		problems = append(problems, Problem{
+			Line:     line,
+			Rule:     rule,
+			Severity: severity,
+			Message:  fmt.Sprintf(format, a...),
+		})
+	}
+
+	// footnote markers of the cells and those of the footnote lines, by
+	// the line each was first seen on
+	markers := make(map[string]int)
+	footnotes := make(map[string]int)
+
+	titleSeen := false
+	headerColumns := 0
+	for i, l := range strings.Split(string(contents), "\n") {
+
+		lineNumber := i + 1
+		l = strings.TrimSuffix(l, "\r")
+
+		if !utf8.ValidString(l) {
+			add(lineNumber, "utf8", SeverityError, "line contains bytes which are not UTF-8")
+		}
+		if strings.TrimRight(l, " \t") != l {
+			add(lineNumber, "whitespace", SeverityWarning, "line has trailing whitespace")
+		}
+
+		trimmedLine := strings.TrimSpace(l)
+		if trimmedLine == "" || trimmedLine == "---" {
+			continue
+		}
+
+		pieces := strings.Split(l, "|")
+
+		// the first line is the title, which a row cannot stand in for
+		if !titleSeen {
+			titleSeen = true
+			if len(pieces) > 1 {
+				add(lineNumber, "title", SeverityError, "table has no title; its first line is a row")
+			} else {
+				for _, m := range footnoteMarker.FindAllString(trimmedLine, -1) {
+					if _, ok := markers[m]; !ok {
+						markers[m] = lineNumber
+					}
+				}
+				continue
+			}
+		}
+
+		// lines other than rows are either footnotes or instructions
+		if len(pieces) < 2 {
+			if m := footnoteMarker.FindString(trimmedLine); m != "" && strings.HasPrefix(trimmedLine, m) {
+				if _, ok := footnotes[m]; ok {
+					add(lineNumber, "footnote", SeverityError, "footnote %s is given more than once", m)
+				} else {
+					footnotes[m] = lineNumber
+				}
+			}
+			continue
+		}
+
+		// every row ought to have as many columns as the header
+		if headerColumns == 0 {
+			headerColumns = len(pieces)
+		} else if len(pieces) != headerColumns {
+			add(lineNumber, "columns", SeverityError, "row has %d columns, but the header has %d",
+				len(pieces), headerColumns)
+		}
+
+		for _, p := range pieces {
+			cell := strings.TrimSpace(p)
+
+			if strings.Contains(cell, ",") {
+				add(lineNumber, "comma", SeverityWarning,
+					"cell %q contains a comma, which CSV output replaces with a space", cell)
+			}
+
+			for _, m := range footnoteMarker.FindAllString(cell, -1) {
+				if _, ok := markers[m]; !ok {
+					markers[m] = lineNumber
+				}
+			}
+		}
+	}
+
+	if !titleSeen {
+		add(0, "title", SeverityError, "table has no title")
+	}
+
+	// every marker needs a footnote, and every footnote a marker
+	for _, m := range sortedKeys(markers) {
+		if _, ok := footnotes[m]; !ok {
+			add(markers[m], "footnote", SeverityError, "footnote marker %s has no footnote", m)
+		}
+	}
+	for _, m := range sortedKeys(footnotes) {
+		if _, ok := markers[m]; !ok {
+			add(footnotes[m], "footnote", SeverityWarning, "footnote %s is not marked in the table", m)
+		}
+	}
+
+	// keep the problems in the order of the lines they are on
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Line < problems[j].Line
+	})
+
+	return problems
+}
+
+// sortedKeys ... the keys of a map of footnote markers, in order
+func sortedKeys(m map[string]int) []string {
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
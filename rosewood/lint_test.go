@@ -0,0 +1,39 @@
+package rosewood
+
+import (
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		rules []string
+	}{
+		{"clean table", "Title\n---\nVariable | Cases\nAge | 45.2\n", nil},
+		{"empty file", "", nil},
+		{"blank lines", "\n\n", []string{"title"}},
+		{"missing title", "Variable | Cases\nAge | 45.2\n", []string{"title"}},
+		{"ragged row", "Title\nVariable | Cases | Controls\nAge | 45.2\n", []string{"columns"}},
+		{"not utf-8", "Title\nVariable | Cases\nAge | 45.2\xff\n", []string{"utf8"}},
+		{"trailing whitespace", "Title \nVariable | Cases\n", []string{"whitespace"}},
+		{"comma", "Title\nVariable | Cases\nAge | 45.2 (40.1, 50.3)\n", []string{"comma"}},
+		{"footnote", "Title\nVariable | Cases\nAge^a | 45.2\n^a Mean\n", nil},
+		{"unmatched marker", "Title\nVariable | Cases\nAge^a | 45.2\n", []string{"footnote"}},
+		{"unmarked footnote", "Title\nVariable | Cases\nAge | 45.2\n^b Mean\n", []string{"footnote"}},
+		{"windows line endings", "Title\r\nVariable | Cases\r\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := Lint([]byte(tt.data))
+			if len(problems) != len(tt.rules) {
+				t.Fatalf("Lint() = %v, want rules %v", problems, tt.rules)
+			}
+			for i, problem := range problems {
+				if problem.Rule != tt.rules[i] {
+					t.Errorf("Lint() rule = %q, want %q", problem.Rule, tt.rules[i])
+				}
+			}
+		})
+	}
+}
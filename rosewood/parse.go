@@ -0,0 +1,118 @@
+package rosewood
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+// IndentUnit ... whitespace denoting a single level of row indentation
+const IndentUnit = "  "
+
+// footnoteMarker ... a footnote marker, e.g. the "^a" of "45.2^a", or the start of a footnote line
+var footnoteMarker = regexp.MustCompile(`\^[a-z]`)
+
+// Parse ... read a Rosewood table from the given reader
+func Parse(r io.Reader) (*Table, error) {
+
+	if r == nil {
+		return nil, fmt.Errorf("Parse() --> invalid input")
+	}
+
+	byteContents, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseLines(strings.Split(string(byteContents), "\n"))
+}
+
+// ParseLines ... turn an array of lines of a Rosewood table into a Table
+func ParseLines(lines []string) (*Table, error) {
+
+	if len(lines) < 1 {
+		return nil, fmt.Errorf("ParseLines() --> invalid input")
+	}
+
+	table := &Table{}
+
+	// the first line is the title and the second the header, with the
+	// body being everything afterwards
+	linesSeen := 0
+	for _, l := range lines {
+
+		trimmedLine := strings.TrimSpace(l)
+
+		if trimmedLine == "" || trimmedLine == "---" {
+			continue
+		}
+
+		linesSeen++
+		if linesSeen == 1 {
+			table.Title = trimmedLine
+			continue
+		}
+
+		// Rosewood instructions and footnotes are exactly one piece, so
+		// check for 2+
+		pieces := strings.Split(l, "|")
+		if len(pieces) < 2 {
+			if m := footnoteMarker.FindString(trimmedLine); m != "" && strings.HasPrefix(trimmedLine, m) {
+				table.Footnotes = append(table.Footnotes, Footnote{
+					Marker: strings.TrimPrefix(m, "^"),
+					Text:   strings.TrimSpace(strings.TrimPrefix(trimmedLine, m)),
+				})
+			}
+			continue
+		}
+
+		row := make([]Cell, 0, len(pieces))
+		for i, p := range pieces {
+
+			cell := Cell{Text: strings.TrimSpace(p), Bold: linesSeen == 2}
+
+			// the first element may be indented by leading whitespace
+			// to denote nested variables
+			if i == 0 {
+				cell.Indent = IndentLevel(p)
+			}
+
+			row = append(row, cell)
+		}
+
+		if len(row) > table.Columns {
+			table.Columns = len(row)
+		}
+
+		if linesSeen == 2 {
+			table.Headers = [][]Cell{row}
+		} else {
+			table.Rows = append(table.Rows, row)
+		}
+	}
+
+	if table.Columns < 2 {
+		return nil, fmt.Errorf("ParseLines() --> empty table given")
+	}
+
+	return table, nil
+}
+
+// IndentLevel ... determine the nesting depth of a Rosewood cell via its leading whitespace
+func IndentLevel(cell string) int {
+
+	width := 0
+	for _, r := range cell {
+		if r == ' ' {
+			width++
+		} else if r == '\t' {
+			width += len(IndentUnit)
+		} else {
+			break
+		}
+	}
+
+	return width / len(IndentUnit)
+}
@@ -0,0 +1,86 @@
+package rosewood
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	baselineTable = `
+Baseline characteristics
+---
+Variable | Cases | Controls
+Age | 45.2 (40.1, 50.3) | 44.0 (39.2, 48.8)
+Sex | |
+  Female | 120 (55%) | 300 (60%)
+    Smoker | 10 | 20
+p-value | <0.001 | 0.25
+`
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		columns int
+		rows    int
+		wantErr bool
+	}{
+		{"empty table", "", 0, 0, true},
+		{"title only", "Title only\n", 0, 0, true},
+		{"baseline table", baselineTable, 3, 5, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := Parse(strings.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if table.Title != "Baseline characteristics" {
+				t.Errorf("Parse() title = %q", table.Title)
+			}
+			if table.Columns != tt.columns || len(table.Rows) != tt.rows {
+				t.Errorf("Parse() columns, rows = %d, %d, want %d, %d", table.Columns, len(table.Rows), tt.columns, tt.rows)
+			}
+			if !table.Headers[0][0].Bold || table.Rows[0][0].Bold {
+				t.Errorf("Parse() only the header cells ought to be bold")
+			}
+			if table.Rows[2][0].Indent != 1 || table.Rows[3][0].Indent != 2 {
+				t.Errorf("Parse() indents = %d, %d, want 1, 2", table.Rows[2][0].Indent, table.Rows[3][0].Indent)
+			}
+		})
+	}
+}
+
+func TestCellValue(t *testing.T) {
+	tests := []struct {
+		text      string
+		valueType string
+		value     float64
+		ok        bool
+		interval  bool
+	}{
+		{"12", ValueFloat, 12, true, false},
+		{"-0.5", ValueFloat, -0.5, true, false},
+		{"55%", ValuePercentage, 0.55, true, false},
+		{"<0.001", ValueFloat, 0.001, true, false},
+		{"p=0.03", ValueFloat, 0.03, true, false},
+		{"1.23 (0.98, 1.54)", "", 0, false, true},
+		{"Female", "", 0, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			cell := Cell{Text: tt.text}
+			valueType, value, ok := cell.Value()
+			if valueType != tt.valueType || value != tt.value || ok != tt.ok {
+				t.Errorf("Value() = %q, %v, %v, want %q, %v, %v", valueType, value, ok, tt.valueType, tt.value, tt.ok)
+			}
+			if cell.IsInterval() != tt.interval {
+				t.Errorf("IsInterval() = %v, want %v", cell.IsInterval(), tt.interval)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package rosewood
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Table ... a parsed Rosewood table
+type Table struct {
+
+	// title of the table, i.e. its first line
+	Title string
+
+	// title of an earlier version of the table, if it changed since, and
+	// the table is part of a comparison
+	OldTitle string
+
+	// number given to the table in its caption
+	Number int
+
+	// cells of the header rows, in order, or nil if the table has none;
+	// Rosewood tables have at most one, but those given as JSON may have
+	// several, e.g. a row of headings spanning the columns of the next
+	Headers [][]Cell
+
+	// cells of the body rows
+	Rows [][]Cell
+
+	// number of columns of the widest row
+	Columns int
+
+	// footnotes given beneath the table, in the order given
+	Footnotes []Footnote
+
+	// source file of the table, or nil if unknown
+	Source *Source
+}
+
+// Cell ... a single cell of a Rosewood table
+type Cell struct {
+
+	// text of the cell, without surrounding whitespace
+	Text string
+
+	// nesting depth of the cell, as given by its leading whitespace
+	Indent int
+
+	// whether the cell is emphasised, as header cells are
+	Bold bool
+
+	// number of columns the cell spans, with 0 being taken as 1
+	Span int
+
+	// how the cell differs from an earlier version of its table, being one
+	// of the Change constants, or blank if it is not part of a comparison
+	Change string
+
+	// text of the cell in the earlier version, if changed
+	OldText string
+}
+
+// Footnote ... a footnote of a table, e.g. "^a Adjusted for age"
+type Footnote struct {
+
+	// letter of the marker, e.g. "a" for ^a
+	Marker string
+
+	// text of the footnote, without its marker
+	Text string
+}
+
+// Source ... details of the file a table was read from, for reproducibility audits
+type Source struct {
+	Path     string
+	Checksum string
+	Modified time.Time
+}
+
+// Caption ... fill in the {n} and {title} of a caption template, e.g. "Table {n}: {title}"
+func (t *Table) Caption(template string) string {
+	caption := strings.Replace(template, "{n}", strconv.Itoa(t.Number), -1)
+	return strings.Replace(caption, "{title}", t.Title, -1)
+}
+
+// Spanned ... the number of columns the cell spans, being at least 1
+func (c Cell) Spanned() int {
+	if c.Span < 1 {
+		return 1
+	}
+	return c.Span
+}
+
+// CellAt ... the cell of a row which begins in the given column, or false if the column is
+// covered by a spanning cell or lies beyond the end of the row
+func CellAt(cells []Cell, column int) (Cell, bool) {
+
+	start := 0
+	for _, cell := range cells {
+		if start == column {
+			return cell, true
+		}
+		if start > column {
+			break
+		}
+		start += cell.Spanned()
+	}
+
+	return Cell{}, false
+}
+
+// RowColumns ... the number of columns the cells of a row span
+func RowColumns(cells []Cell) int {
+
+	columns := 0
+	for _, cell := range cells {
+		columns += cell.Spanned()
+	}
+
+	return columns
+}
+
+// String ... describe the source of a table as a single line of text
+func (s *Source) String() string {
+	return "Source: " + s.Path + "; SHA-256: " + s.Checksum +
+		"; modified: " + s.Modified.Format("2006-01-02 15:04:05 MST")
+}
@@ -0,0 +1,52 @@
+package rosewood
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// value types of numeric cells
+const (
+	ValueFloat      = "float"
+	ValuePercentage = "percentage"
+)
+
+var (
+	// numeric cell contents, e.g. 12, -0.5, 45%, <0.001, p=0.03
+	numericRegex = regexp.MustCompile(`^(?:[pP]\s*)?[<>=]?\s*([-+]?\d+(?:\.\d+)?)(%?)$`)
+
+	// estimate with an interval, e.g. 1.23 (0.98, 1.54) or 120 (55%)
+	intervalRegex = regexp.MustCompile(`^[<>]?\s*[-+]?\d+(\.\d+)?%?\s*[(\[].*[)\]]$`)
+)
+
+// Value ... determine the value type and value of a numeric cell; percentages
+// are given as fractions, e.g. 55% --> 0.55
+func (c Cell) Value() (string, float64, bool) {
+
+	matches := numericRegex.FindStringSubmatch(c.Text)
+	if len(matches) != 3 {
+		return "", 0, false
+	}
+
+	value, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return "", 0, false
+	}
+
+	if matches[2] == "%" {
+		return ValuePercentage, value / 100, true
+	}
+
+	return ValueFloat, value, true
+}
+
+// IsNumeric ... whether the cell holds a number, percentage or p-value
+func (c Cell) IsNumeric() bool {
+	_, _, ok := c.Value()
+	return ok
+}
+
+// IsInterval ... whether the cell holds an estimate with an interval
+func (c Cell) IsInterval() bool {
+	return intervalRegex.MatchString(c.Text)
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"crypto/sha256"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rbisewski/scaffolding/csvout"
+	"github.com/rbisewski/scaffolding/jsonout"
+	"github.com/rbisewski/scaffolding/odt"
+	"github.com/rbisewski/scaffolding/rosewood"
+)
+
+// content types of the formats served
+const (
+	ContentTypeODT  = "application/vnd.oasis.opendocument.text"
+	ContentTypeCSV  = "text/csv; charset=utf-8"
+	ContentTypeJSON = "application/json"
+)
+
+// serve ... run the HTTP conversion service until it fails
+func serve(args []string) error {
+
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Println(serveUsageMessage)
+	}
+
+	addr := flags.String("addr", ":8080", "")
+	maxSize := flags.Int64("max-size", DefaultMaxRequestSize, "")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *maxSize < 1 {
+		return fmt.Errorf("Invalid maximum request size. Please enter a whole number of bytes.")
+	}
+
+	server := &Server{
+		maxSize:   *maxSize,
+		templates: make(map[string]*odt.Template),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/convert", server.convert)
+
+	fmt.Printf("Serving table conversions on %s...\n", *addr)
+
+	return http.ListenAndServe(*addr, mux)
+}
+
+// convert ... turn the Rosewood tables of the request body, or of its multipart uploads, into the
+// requested format
+func (s *Server) convert(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Please POST the Rosewood tables to convert.", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := requestFormat(r)
+	if format != "odt" && format != "csv" && format != "json" {
+		http.Error(w, "Unsupported format. Please request odt, csv or json.", http.StatusNotAcceptable)
+		return
+	}
+
+	// the size limit applies to the whole body, uploads included
+	r.Body = http.MaxBytesReader(w, r.Body, s.maxSize)
+
+	tables, err := requestTables(r)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			http.Error(w, fmt.Sprintf("Request too large. Please send at most %d bytes.", s.maxSize),
+				http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// the writer of the requested format, whose theme may be a preset
+	var writer io.WriterTo
+	switch format {
+
+	case "csv":
+		csvWriter := csvout.NewWriter()
+		for _, table := range tables {
+			csvWriter.AddTable(table)
+		}
+		writer = csvWriter
+		w.Header().Set("Content-Type", ContentTypeCSV)
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+DefaultCSVOutputFilename+"\"")
+
+	case "json":
+		jsonWriter := jsonout.NewWriter()
+		jsonWriter.Generator = "scaffolding v" + Version + ", build " + Build
+		for _, table := range tables {
+			jsonWriter.AddTable(table)
+		}
+		writer = jsonWriter
+		w.Header().Set("Content-Type", ContentTypeJSON)
+
+	case "odt":
+		template, err := s.template(r.URL.Query().Get("template"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		odtWriter := odt.NewWriter(template)
+		if preset := r.URL.Query().Get("preset"); preset != "" {
+			theme, ok := odt.Presets[strings.ToLower(preset)]
+			if !ok {
+				http.Error(w, "Invalid preset: "+preset+". Please use apa, ama or nejm.", http.StatusBadRequest)
+				return
+			}
+			odtWriter.Theme = theme
+		}
+		odtWriter.Metadata.Generator = "scaffolding v" + Version + ", build " + Build
+		for _, table := range tables {
+			odtWriter.AddTable(table)
+		}
+		writer = odtWriter
+		w.Header().Set("Content-Type", ContentTypeODT)
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+DefaultODTOutputFilename+"\"")
+	}
+
+	// nothing is written before the layout of the tables is known to be
+	// valid, so errors may still be sent as such
+	n, err := writer.WriteTo(w)
+	if err != nil && n == 0 {
+		w.Header().Del("Content-Disposition")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+// template ... the ODT template of the given name, read in once and then kept
+func (s *Server) template(name string) (*odt.Template, error) {
+
+	if name == "" {
+		name = "odt_blank_template"
+	}
+
+	// only the templates directory itself may be read from
+	if name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("Invalid template: %s", name)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if template, ok := s.templates[name]; ok {
+		return template, nil
+	}
+
+	template, err := odt.ReadTemplate(filepath.Join(DefaultTemplatesDir, name))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid template: %s", name)
+	}
+	s.templates[name] = template
+
+	return template, nil
+}
+
+// requestFormat ... the format requested via the format query parameter, else the Accept header,
+// else ODT; of the types accepted, those of the formats served are preferred over wildcards
+func requestFormat(r *http.Request) string {
+
+	if format := r.URL.Query().Get("format"); format != "" {
+		return strings.ToLower(format)
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return "odt"
+	}
+
+	formats := map[string]string{
+		"application/vnd.oasis.opendocument.text": "odt",
+		"text/csv":         "csv",
+		"application/json": "json",
+	}
+
+	// the most preferred of the formats served wins, the first of them
+	// if equally preferred
+	format := ""
+	bestQuality := 0.0
+	wildcard := false
+	for _, entry := range strings.Split(accept, ",") {
+
+		mediaType, params, err := mime.ParseMediaType(entry)
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		if mediaType == "*/*" {
+			wildcard = true
+		} else if f, ok := formats[mediaType]; ok && quality > bestQuality {
+			format = f
+			bestQuality = quality
+		}
+	}
+
+	switch {
+	case format != "":
+		return format
+	case wildcard:
+		return "odt"
+	}
+
+	return accept
+}
+
+// requestTables ... parse the Rosewood tables of the request, being either its body or each of
+// its multipart uploads, numbered in the order given
+func requestTables(r *http.Request) ([]*rosewood.Table, error) {
+
+	tables := make([]*rosewood.Table, 0)
+	failures := ""
+
+	addTable := func(name string, reader io.Reader) error {
+
+		byteContents, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+
+		table, err := parseTable(byteContents)
+		if err != nil {
+			failures += "\n  " + name + ": " + err.Error()
+			return nil
+		}
+
+		table.Number = len(tables) + 1
+		table.Source = &rosewood.Source{
+			Path:     name,
+			Checksum: fmt.Sprintf("%x", sha256.Sum256(byteContents)),
+			Modified: time.Now(),
+		}
+		tables = append(tables, table)
+
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		if err := addTable("table", r.Body); err != nil {
+			return nil, err
+		}
+	} else {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			return nil, err
+		}
+
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			name := part.FileName()
+			if name == "" {
+				name = part.FormName()
+			}
+
+			err = addTable(name, part)
+			part.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if failures != "" {
+		return nil, fmt.Errorf("Unable to convert the tables:%s", failures)
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("No tables given. Please send Rosewood text or upload Rosewood files.")
+	}
+
+	return tables, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		accept string
+		want   string
+	}{
+		{"nothing requested", "/convert", "", "odt"},
+		{"query parameter", "/convert?format=CSV", "application/json", "csv"},
+		{"odt", "/convert", "application/vnd.oasis.opendocument.text", "odt"},
+		{"csv", "/convert", "text/csv", "csv"},
+		{"json with charset", "/convert", "application/json; charset=utf-8", "json"},
+		{"wildcard", "/convert", "*/*", "odt"},
+		{"csv before wildcard", "/convert", "text/csv, */*;q=0.1", "csv"},
+		{"wildcard before csv", "/convert", "*/*;q=0.1, text/csv", "csv"},
+		{"preferred json", "/convert", "text/csv;q=0.5, application/json", "json"},
+		{"refused csv", "/convert", "text/csv;q=0, */*", "odt"},
+		{"unsupported", "/convert", "text/html", "text/html"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", tt.url, nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			if got := requestFormat(r); got != tt.want {
+				t.Errorf("requestFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
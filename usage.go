@@ -3,18 +3,84 @@ package main
 const usageMessage = `
 Convert rosewood tables into ISO standard ODT files or CSV files.
 
-Usage: identify_conditions
+Commands:
+	convert       Converts tables into an ODT, CSV or JSON file (default)
+	validate      Checks tables for problems, such as ragged rows
+	inspect       Prints the parsed structure of tables
+	diff          Prints the rows which changed between two versions of tables
+	serve         Serves table conversions over HTTP
+	templates     Lists the available ODT templates
+	help          Prints the usage message of a command; e.g. "help diff"
+
+Usage: identify_conditions [convert]
        -csv
+       -format <odt|csv|json>
+       -index
+       -title-page
+       -provenance
        -tables <comma,separated,list,of,tables>
        -indir  <path_to_input_directory>
        -outdir <path_to_output_directory>
+       -jobs <n>
+       -keep-going
+       -watch
+       -align  <table=alignments,...>
+       -widths <table=cm:cm:...,...>
+       -max-col-width <cm>
+       -landscape <comma,separated,list,of,tables>
+       -preset <apa|ama|nejm>
+       -theme  <path_to_theme_file>
+       -caption <caption_template>
+       -start-number <n>
+       -numbers <table=n,...>
+       -title <document_title> -author <name> -subject <subject>
+       -keywords <comma,separated,list,of,keywords>
+       -header <left|centre|right> -footer <left|centre|right>
 
 Arguments:
 	h, help       Prints this usage message
   	version       Prints the current program version and build info
 	csv           Prints the given rosewood tables as plain-text CSVs (default is ODT)
+	format        Output format: odt, csv or json (default odt); JSON gives the title,
+	              number, header rows, rows of cells with their text, indent, bold
+	              and span, footnotes and source file of each table
+	index         Begins the ODT with a list of tables and their page numbers
+	title-page    Begins the ODT with a title page of the title, subject, author and date
+	provenance    Follows each table with its source file path, SHA-256, modification time
+	              and the version of scaffolding that converted it
 	tables        Comma separated list of tables; e.g. "table-w-conditions,table-wo-screening"
+	              Each may be a Rosewood table or a single table in the JSON form of
+	              -format json, which may have several header rows
 	outdir        Output location; e.g. /path/to/output/directory
+	jobs          Number of tables to read and parse at once (default is the number of CPUs)
+	keep-going    Replaces tables that fail to convert with a note of the error, then lists
+	              the failures and exits with code 1 once the output is written
+	watch         Keeps running, rebuilding the output a second after any of the tables
+	              change; unchanged tables are not parsed again
+	align         Per-table column alignments of l(eft), c(entre), r(ight), d(ecimal),
+	              p(arenthesis) or a(uto); e.g. "table-w-conditions=lddp"
+	widths        Per-table colon separated column widths in cm, blank or a(uto) to
+	              estimate from the cell text; e.g. "table-w-conditions=5.5:a:3"
+	max-col-width Widest estimated column width in cm (default 6)
+	landscape     Tables to place on landscape pages; tables too wide for a portrait
+	              page are placed on landscape pages regardless
+	preset        Journal table style: apa, ama or nejm; e.g. three-line rules
+	theme         JSON file of title_font, title_colour, title_size, body_font, body_size,
+	              borders (grid, three-line, horizontal or none), border_line,
+	              cell_padding, header_shading, zebra_striping and caption (inline,
+	              label-bold or stacked) settings, applied on top of any preset
+	caption       Caption of every table; e.g. "Supplementary Table S{n}. {title}"
+	start-number  Number of the first table (default 1)
+	numbers       Per-table numbers, which later tables continue from; e.g. "table-w-conditions=7"
+	title         Document title of the ODT
+	author        Document author of the ODT
+	subject       Document subject of the ODT
+	keywords      Comma separated list of document keywords of the ODT
+	header        Page header of "left|centre|right" sections, which may contain {report},
+	              {date}, {page}, {pages} and {source} (the current table file);
+	              e.g. "{report}||CONFIDENTIAL"
+	footer        Page footer in the same form as the header (default "||{page}");
+	              e.g. "{source}|{date}|Page {page} of {pages}"
 
 	Description:
 		The ODT values created by this program can be read by Libreoffice or
@@ -35,3 +101,107 @@ Arguments:
 		variable, ci of cases, ci of controls
 		col_1, ci_cases_value, ci_of_controls
 		col_2, ci_cases_value, ci_of_controls`
+
+const serveUsageMessage = `
+Serve table conversions over HTTP.
+
+Usage: scaffolding serve
+       -addr <host:port>
+       -max-size <bytes>
+
+Arguments:
+	addr          Address to listen on (default ":8080")
+	max-size      Largest request accepted, in bytes (default 10485760)
+
+Endpoints:
+	POST /convert
+	              Converts the Rosewood table of the request body, or each of the
+	              Rosewood files of a multipart/form-data upload, in the order given.
+
+	              The format is chosen by the ?format=odt|csv|json query parameter,
+	              else by an Accept header of application/vnd.oasis.opendocument.text,
+	              text/csv or application/json, else ODT. The ?template=<name> parameter selects an ODT
+	              template of the templates directory, and ?preset=apa|ama|nejm a
+	              journal table style.
+
+	              e.g. curl --data-binary @table-w-conditions "localhost:8080/convert?format=csv"`
+
+const validateUsageMessage = `
+Check each of the given rosewood tables for problems, without producing output.
+
+Usage: scaffolding validate
+       -json
+       -tables <comma,separated,list,of,tables>
+       -indir  <path_to_input_directory>
+       -jobs <n>
+
+Arguments:
+	json          Prints the problems of each table as JSON, e.g. for CI checks
+	tables        Comma separated list of tables; e.g. "table-w-conditions,table-wo-screening"
+	indir         Input location; e.g. /path/to/input/directory
+	jobs          Number of tables to read and parse at once (default is the number of CPUs)
+
+	Description:
+		Tables are checked for rows with a different number of columns to the
+		header, missing titles, duplicate titles, bytes which are not UTF-8
+		and footnote markers, such as ^a, without a footnote line; these are
+		errors, which cause an exit code of 1. Footnotes which are never
+		marked, cells with commas, which CSV output replaces with spaces,
+		and trailing whitespace are warnings.`
+
+const inspectUsageMessage = `
+Print the parsed structure of each of the given rosewood tables.
+
+Usage: scaffolding inspect
+       -json
+       -tables <comma,separated,list,of,tables>
+       -indir  <path_to_input_directory>
+       [path_to_table ...]
+
+Arguments:
+	json          Prints the structure as JSON rather than as a tree
+	tables        Comma separated list of tables; e.g. "table-w-conditions,table-wo-screening"
+	indir         Input location; e.g. /path/to/input/directory
+
+	Description:
+		Prints the title, number of columns and header rows, then each cell
+		of each row along with its indentation level, emphasis and whether it
+		was recognised as a number, percentage, interval or text. Rows with
+		fewer cells than the table has columns are noted as padded by empty
+		cells. Tables may also be given as paths; e.g. "inspect table.txt".`
+
+const diffUsageMessage = `
+Print the rows added, removed or changed between two versions of a rosewood
+table, or between the tables of the same name in two directories.
+
+Usage: scaffolding diff
+       -indir  <path_to_input_directory>
+       -odt    <path_to_redline_odt>
+       -track-changes
+       <old_table|old_directory> <new_table|new_directory>
+
+Arguments:
+	indir         Input location of relative paths; e.g. /path/to/input/directory
+	odt           Also writes the new tables to an ODT file, with added cells
+	              highlighted green, removed cells struck out in red and changed
+	              cells showing the old value struck out before the new one
+	track-changes Writes the changes to the -odt file as tracked changes, which may
+	              be accepted or rejected cell by cell in LibreOffice, rather than
+	              highlighting them; titles and cell text are tracked, but rows are not,
+	              so rejecting every change leaves an empty row in place of each added
+	              row, and accepting every change one in place of each removed row
+
+	Description:
+		Rows are matched by their first cell, along with the first cells of
+		the rows they are nested under; e.g. "Sex > Female". Added rows are
+		prefixed by "+", removed rows by "-", and each changed cell by "~",
+		naming the column it is under.`
+
+const templatesUsageMessage = `
+List the ODT templates available to the serve command.
+
+Usage: scaffolding templates
+       -dir <path_to_templates_directory>
+
+Arguments:
+	dir           Templates location (default "templates")`